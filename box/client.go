@@ -1,10 +1,13 @@
 package box
 
 import (
-	"errors"
 	"io"
 	"io/ioutil"
 	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
 )
 
 const (
@@ -13,24 +16,156 @@ const (
 )
 
 type Client interface {
+	// WithContext returns a Client that sends its requests with ctx, so that
+	// they are abandoned when ctx is cancelled or its deadline passes. It
+	// stands in for a context-aware variant of every method:
+	// c.WithContext(ctx).DownloadFile(id, destPath) is DownloadFile bound to
+	// ctx, and the same goes for the others. Requests are bound with
+	// http.Request.WithContext, which needs Go 1.7.
+	WithContext(ctx context.Context) Client
+	// IfMatch returns a Client whose updates, moves, deletions and uploads of
+	// new versions only succeed if the item still has etag, failing with an
+	// error that satisfies IsPreconditionFailed if it was changed since.
+	// Requests that create items are not affected.
+	IfMatch(etag string) Client
+	// IfNoneMatch returns a Client whose GetFile and GetFolder return
+	// ErrNotModified instead of the item if it still has etag. Other requests,
+	// such as listings and downloads, are not affected.
+	IfNoneMatch(etag string) Client
+	// AsUser returns a Client that acts on behalf of the managed user userID,
+	// which requires the current user to be an enterprise admin.
+	AsUser(userID string) Client
+	RequestStats() (api, upload GovernorStats)
+	// ChunkedUploadThreshold returns the file size in bytes at and above
+	// which uploads go through an upload session.
+	ChunkedUploadThreshold() int64
+
 	Get(endpointPath string) ([]byte, error)
 	GetByURL(url string) ([]byte, error)
 	Post(endpointPath, bodyType string, body io.Reader, upload bool) ([]byte, error)
+	Put(endpointPath, bodyType string, body io.Reader, upload bool) ([]byte, error)
 	Delete(endpointPath string) ([]byte, error)
 	Options(endpointPath string) ([]byte, error)
 
 	GetCurrentUser() (*User, error)
+	GetUser(id string) (*User, error)
+	GetEnterpriseUsers(filter string) ([]User, error)
+	CreateUser(attr UserAttributes) (*User, error)
+	UpdateUser(id string, attr UserAttributes) (*User, error)
+	DeactivateUser(id string) (*User, error)
+	TransferOwnedContent(fromUserID, toUserID string, notify bool) (*Folder, error)
+
+	GetGroups() ([]Group, error)
+	CreateGroup(name, description string) (*Group, error)
+	DeleteGroup(id string) error
+	GetGroupMembers(groupID string) ([]GroupMembership, error)
+	AddGroupMember(groupID, userID, role string) (*GroupMembership, error)
+	RemoveGroupMember(membershipID string) error
 
 	CreateFolder(name, parentID string) (*Folder, error)
 	GetFolder(id string) (*Folder, error)
 	GetFolderContents(id string) (*FolderContents, error)
+	GetFolderItems(id string, opts *ItemsOptions) *ItemIterator
 	DeleteFolder(id string, recursive bool) error
+	MoveFolder(id, parentID, name string) (*Folder, error)
+	RenameFolder(id, name string) (*Folder, error)
+	CopyFolder(id, parentID, name string) (*Folder, error)
+
+	GetWebLink(id string) (*WebLink, error)
+	CreateWebLink(url, parentID, name, description string) (*WebLink, error)
+	UpdateWebLink(id string, update WebLinkUpdate) (*WebLink, error)
+	MoveWebLink(id, parentID, name string) (*WebLink, error)
+	DeleteWebLink(id string) error
+
+	GetCollections() ([]ItemCollection, error)
+	GetFavorites() (*ItemCollection, error)
+	GetCollectionItems(id string, opts *ItemsOptions) *ItemIterator
+	AddToCollection(collectionID, itemType, itemID string) error
+	RemoveFromCollection(collectionID, itemType, itemID string) error
 
 	GetFile(id string) (*File, error)
 	DownloadFile(id, destPath string) error
+	DownloadFileTo(id string, w io.Writer) error
+	DownloadFileRange(id string, w io.Writer, offset, length int64) (int64, error)
 	UploadFile(srcPath, parentID string) (*File, error)
+	UploadReader(r io.Reader, name, parentID string, size int64) (*File, error)
 	UploadFileVersion(fileID, srcPath string) (*File, error)
+	UploadFileVersionReader(fileID string, r io.Reader, name string, size int64) (*File, error)
+	PreflightUpload(name, parentID string, size int64) error
+	PreflightUploadVersion(fileID, name string, size int64) error
 	DeleteFile(id string) error
+	MoveFile(id, parentID, name string) (*File, error)
+	RenameFile(id, name string) (*File, error)
+	CopyFile(id, parentID, name string) (*File, error)
+	LockFile(id string, expiresAt *time.Time, preventDownload bool) (*Lock, error)
+	UnlockFile(id string) error
+
+	GetFileVersions(fileID string) ([]FileVersion, error)
+	DownloadFileVersion(fileID, versionID string, w io.Writer) error
+	PromoteFileVersion(fileID, versionID string) (*FileVersion, error)
+	DeleteFileVersion(fileID, versionID string) error
+
+	GetThumbnail(fileID, extension string, opts *ThumbnailOptions, w io.Writer) error
+	GetRepresentations(fileID string, hints ...string) ([]Representation, error)
+	WaitForRepresentation(rep *Representation) (*Representation, error)
+	DownloadRepresentation(rep *Representation, assetPath string, w io.Writer) error
+
+	GetTrashItems(opts *ItemsOptions) *ItemIterator
+	RestoreFile(id, parentID, name string) (*File, error)
+	RestoreFolder(id, parentID, name string) (*Folder, error)
+	PurgeFile(id string) error
+	PurgeFolder(id string) error
+
+	SetFileSharedLink(id string, settings *SharedLinkSettings) (*SharedLink, error)
+	RemoveFileSharedLink(id string) error
+	SetFolderSharedLink(id string, settings *SharedLinkSettings) (*SharedLink, error)
+	RemoveFolderSharedLink(id string) error
+
+	Search(query string, opts *SearchOptions) *ItemIterator
+
+	GetFileComments(fileID string) ([]Comment, error)
+	AddComment(fileID, message string) (*Comment, error)
+	ReplyToComment(commentID, message string) (*Comment, error)
+	UpdateComment(id, message string) (*Comment, error)
+	DeleteComment(id string) error
+
+	GetFileTasks(fileID string) ([]Task, error)
+	CreateTask(fileID, action, message string, dueAt *time.Time) (*Task, error)
+	DeleteTask(id string) error
+	GetTaskAssignments(taskID string) ([]TaskAssignment, error)
+	AssignTask(taskID string, assignee TaskAssignee) (*TaskAssignment, error)
+	UpdateTaskAssignment(id, resolutionState, message string) (*TaskAssignment, error)
+	DeleteTaskAssignment(id string) error
+
+	GetMetadataTemplates(scope string) ([]MetadataTemplate, error)
+	GetMetadataTemplate(scope, templateKey string) (*MetadataTemplate, error)
+	GetFileMetadataList(fileID string) ([]Metadata, error)
+	GetFileMetadata(fileID, scope, templateKey string) (Metadata, error)
+	CreateFileMetadata(fileID, scope, templateKey string, values map[string]interface{}) (Metadata, error)
+	UpdateFileMetadata(fileID, scope, templateKey string, ops []MetadataOperation) (Metadata, error)
+	DeleteFileMetadata(fileID, scope, templateKey string) error
+	GetFolderMetadataList(folderID string) ([]Metadata, error)
+	GetFolderMetadata(folderID, scope, templateKey string) (Metadata, error)
+	CreateFolderMetadata(folderID, scope, templateKey string, values map[string]interface{}) (Metadata, error)
+	UpdateFolderMetadata(folderID, scope, templateKey string, ops []MetadataOperation) (Metadata, error)
+	DeleteFolderMetadata(folderID, scope, templateKey string) error
+	QueryMetadata(query MetadataQuery) (*FolderContents, error)
+
+	GetFolderCollaborations(folderID string) ([]Collaboration, error)
+	GetPendingCollaborations() ([]Collaboration, error)
+	AddCollaboration(folderID string, collaborator Collaborator, role string) (*Collaboration, error)
+	SetCollaborationRole(id, role string) (*Collaboration, error)
+	AcceptCollaboration(id string) (*Collaboration, error)
+	RejectCollaboration(id string) (*Collaboration, error)
+	DeleteCollaboration(id string) error
+
+	CreateUploadSession(parentID, fileName string, fileSize int64) (*UploadSession, error)
+	CreateUploadSessionVersion(fileID string, fileSize int64) (*UploadSession, error)
+	GetUploadSession(sessionID string) (*UploadSession, error)
+	UploadPart(sessionID string, data []byte, offset, fileSize int64) (*UploadPart, error)
+	ListUploadParts(sessionID string) ([]UploadPart, error)
+	CommitUploadSession(sessionID, sha1 string, parts []UploadPart) (*File, error)
+	AbortUploadSession(sessionID string) error
 
 	GetEvents(streamPosition string) (*EventCollection, error)
 	GetLongPollURL() (string, error)
@@ -38,66 +173,233 @@ type Client interface {
 }
 
 type client struct {
-	client           *http.Client
-	apiBaseURL       string
-	apiUploadBaseURL string
+	client                 *http.Client
+	apiBaseURL             string
+	apiUploadBaseURL       string
+	chunkedUploadThreshold int64
+	retryPolicy            RetryPolicy
+	apiGovernor            *Governor
+	uploadGovernor         *Governor
+	ctx                    context.Context
+	ifMatch                string // Sent as If-Match with requests that change an item.
+	ifNoneMatch            string // Sent as If-None-Match by GetFile and GetFolder.
+	asUser                 string // Sent as As-User with every request.
+}
+
+// Option configures a Client created by NewClient.
+type Option func(*client)
+
+// WithRetryPolicy sets the policy used to retry requests that fail with a
+// transient error.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *client) {
+		c.retryPolicy = policy
+	}
+}
+
+// WithChunkedUploadThreshold sets the file size in bytes at and above which
+// uploads go through an upload session instead of a single request. Sizes
+// below MinChunkedUploadSize are raised to it, as Box does not accept smaller
+// sessions.
+func WithChunkedUploadThreshold(size int64) Option {
+	return func(c *client) {
+		if size < MinChunkedUploadSize {
+			size = MinChunkedUploadSize
+		}
+		c.chunkedUploadThreshold = size
+	}
+}
+
+// WithGovernors sets the governors that limit the rate and concurrency of
+// requests to the API host and to the upload host respectively. Passing the
+// same governors to several clients makes them share the limits. A nil
+// governor leaves requests to that host unlimited.
+func WithGovernors(api, upload *Governor) Option {
+	return func(c *client) {
+		c.apiGovernor = api
+		c.uploadGovernor = upload
+	}
 }
 
-func NewClient(httpClient *http.Client) Client {
-	return &client{
-		client:           httpClient,
-		apiBaseURL:       defaultAPIBaseURL,
-		apiUploadBaseURL: defaultAPIUploadURL,
+func NewClient(httpClient *http.Client, options ...Option) Client {
+	c := &client{
+		client:                 httpClient,
+		apiBaseURL:             defaultAPIBaseURL,
+		apiUploadBaseURL:       defaultAPIUploadURL,
+		chunkedUploadThreshold: DefaultChunkedUploadThreshold,
+		retryPolicy:            DefaultRetryPolicy,
+		apiGovernor:            NewGovernor(DefaultAPIRequestsPerSecond, DefaultAPIBurst, DefaultAPIMaxInFlight),
+		uploadGovernor:         NewGovernor(DefaultUploadRequestsPerSecond, DefaultUploadBurst, DefaultUploadMaxInFlight),
+		ctx:                    context.Background(),
+	}
+	for _, option := range options {
+		option(c)
 	}
+	return c
+}
+
+func (c *client) WithContext(ctx context.Context) Client {
+	c2 := *c
+	c2.ctx = ctx
+	return &c2
+}
+
+func (c *client) IfMatch(etag string) Client {
+	c2 := *c
+	c2.ifMatch = etag
+	return &c2
+}
+
+func (c *client) IfNoneMatch(etag string) Client {
+	c2 := *c
+	c2.ifNoneMatch = etag
+	return &c2
+}
+
+func (c *client) AsUser(userID string) Client {
+	c2 := *c
+	c2.asUser = userID
+	return &c2
+}
+
+// RequestStats returns the statistics of the governors of requests to the API
+// host and to the upload host.
+func (c *client) RequestStats() (api, upload GovernorStats) {
+	if c.apiGovernor != nil {
+		api = c.apiGovernor.Stats()
+	}
+	if c.uploadGovernor != nil {
+		upload = c.uploadGovernor.Stats()
+	}
+	return api, upload
+}
+
+func (c *client) ChunkedUploadThreshold() int64 {
+	return c.chunkedUploadThreshold
 }
 
 func (c *client) Get(endpointPath string) ([]byte, error) {
 	return c.GetByURL(c.endpointURL(endpointPath))
 }
 
+// getItem gets the file or folder at endpointPath, unless it still has the
+// client's If-None-Match ETag.
+func (c *client) getItem(endpointPath string) ([]byte, error) {
+	req, err := c.newRequest("GET", c.endpointURL(endpointPath), nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.ifNoneMatch != "" {
+		req.Header.Set("If-None-Match", c.ifNoneMatch)
+	}
+	return c.do(req)
+}
+
 func (c *client) GetByURL(url string) ([]byte, error) {
-	r, err := c.client.Get(url)
+	req, err := c.newRequest("GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
-	defer r.Body.Close()
-	return handleResponse(r)
+	return c.do(req)
 }
 
 func (c *client) Post(endpointPath, bodyType string, body io.Reader, upload bool) ([]byte, error) {
-	url := c.endpointURL(endpointPath)
-	if upload {
-		url = c.uploadEndpointURL(endpointPath)
-	}
-	r, err := c.client.Post(url, bodyType, body)
+	return c.sendBody("POST", endpointPath, bodyType, body, upload)
+}
+
+func (c *client) Put(endpointPath, bodyType string, body io.Reader, upload bool) ([]byte, error) {
+	return c.sendBody("PUT", endpointPath, bodyType, body, upload)
+}
+
+func (c *client) Delete(endpointPath string) ([]byte, error) {
+	req, err := c.newRequest("DELETE", c.endpointURL(endpointPath), nil)
 	if err != nil {
 		return nil, err
 	}
-	defer r.Body.Close()
-	return handleResponse(r)
+	return c.do(req)
 }
 
-func (c *client) Delete(endpointPath string) ([]byte, error) {
-	req, err := http.NewRequest("DELETE", c.endpointURL(endpointPath), nil)
+func (c *client) Options(endpointPath string) ([]byte, error) {
+	req, err := c.newRequest("OPTIONS", c.endpointURL(endpointPath), nil)
 	if err != nil {
 		return nil, err
 	}
+	return c.do(req)
+}
 
-	r, err := c.client.Do(req)
+func (c *client) sendBody(method, endpointPath, bodyType string, body io.Reader, upload bool) ([]byte, error) {
+	url := c.endpointURL(endpointPath)
+	if upload {
+		url = c.uploadEndpointURL(endpointPath)
+	}
+	req, err := c.newRequest(method, url, body)
 	if err != nil {
 		return nil, err
 	}
-	defer r.Body.Close()
-	return handleResponse(r)
+	req.Header.Set("Content-Type", bodyType)
+	return c.do(req)
 }
 
-func (c *client) Options(endpointPath string) ([]byte, error) {
-	req, err := http.NewRequest("OPTIONS", c.endpointURL(endpointPath), nil)
+// newRequest returns a request that is cancelled along with the client's
+// context and carries the client's As-User header.
+func (c *client) newRequest(method, url string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequest(method, url, body)
 	if err != nil {
 		return nil, err
 	}
+	if c.asUser != "" {
+		req.Header.Set("As-User", c.asUser)
+	}
+	return req.WithContext(c.requestContext()), nil
+}
+
+// setIfMatch makes req only succeed if the item it changes still has the
+// client's If-Match ETag.
+func (c *client) setIfMatch(req *http.Request) {
+	if c.ifMatch != "" {
+		req.Header.Set("If-Match", c.ifMatch)
+	}
+}
 
-	r, err := c.client.Do(req)
+// requestContext returns the context requests are made with.
+func (c *client) requestContext() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
+// governor returns the governor for requests to the host of req, or nil for
+// requests to other hosts such as the long poll server.
+func (c *client) governor(req *http.Request) *Governor {
+	url := req.URL.String()
+	switch {
+	case c.apiUploadBaseURL != c.apiBaseURL && strings.HasPrefix(url, c.apiUploadBaseURL):
+		return c.uploadGovernor
+	case strings.HasPrefix(url, c.apiBaseURL):
+		return c.apiGovernor
+	}
+	return nil
+}
+
+// sleep pauses for d, returning early with the context's error if it is
+// cancelled in the meantime.
+func (c *client) sleep(d time.Duration) error {
+	ctx := c.requestContext()
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// do sends req and returns the response body, or an error if the request
+// failed or the response status indicates an error.
+func (c *client) do(req *http.Request) ([]byte, error) {
+	r, err := c.send(req)
 	if err != nil {
 		return nil, err
 	}
@@ -119,8 +421,11 @@ func handleResponse(r *http.Response) ([]byte, error) {
 		return nil, err
 	}
 
+	if r.StatusCode == http.StatusNotModified {
+		return nil, ErrNotModified
+	}
 	if r.StatusCode >= 400 {
-		return nil, errors.New(r.Status + " -- " + string(body))
+		return nil, newAPIError(r, body)
 	}
 
 	return body, nil
@@ -0,0 +1,124 @@
+package box
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+func TestWithContextCancelsRequests(t *testing.T) {
+	attempts := 0
+	server, client := newTestHandlerClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	client.retryPolicy = RetryPolicy{MaxRetries: 5, BaseDelay: time.Hour}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.WithContext(ctx).GetCurrentUser()
+	assert.Equal(t, context.DeadlineExceeded, err, "Request should stop when the context expires")
+	assert.Equal(t, 1, attempts, "Request should not be retried after the context expires")
+	assert.True(t, time.Since(start) < time.Minute, "Retry delay should be interrupted")
+
+	_, err = client.WithContext(ctx).GetCurrentUser()
+	assert.Error(t, err, "Requests with an expired context should fail")
+	assert.Equal(t, 1, attempts, "Requests with an expired context should not be sent")
+}
+
+func TestIfMatch(t *testing.T) {
+	const etag = "3"
+	var methods []string
+	server, client := newTestHandlerClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" || r.Method == "POST" || !strings.HasPrefix(r.URL.Path, "/files/5") {
+			assert.Empty(t, r.Header.Get("If-Match"), "If-Match should only be sent when changing an item")
+		}
+		if strings.HasPrefix(r.URL.Path, "/files/upload_sessions/") {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if r.Method != "GET" && r.Method != "POST" && r.Header.Get("If-Match") != etag {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			fmt.Fprintln(w, `{"type": "error", "status": 412, "code": "precondition_failed"}`)
+			return
+		}
+		methods = append(methods, r.Method)
+		fmt.Fprintln(w, `{"type": "file", "id": "5", "etag": "4"}`)
+	}))
+	defer server.Close()
+
+	_, err := client.RenameFile("5", "new.txt")
+	assert.True(t, IsPreconditionFailed(err), "Rename without the expected ETag should fail")
+
+	conditional := client.IfMatch(etag)
+	_, err = conditional.GetFile("5")
+	assert.NoError(t, err, "Function should not return error")
+	_, err = conditional.CreateFolder("new", "0")
+	assert.NoError(t, err, "Function should not return error")
+	_, err = conditional.RenameFile("5", "new.txt")
+	assert.NoError(t, err, "Rename with the expected ETag should succeed")
+	err = conditional.DeleteFile("5")
+	assert.NoError(t, err, "Delete with the expected ETag should succeed")
+	assert.Equal(t, []string{"GET", "POST", "PUT", "DELETE"}, methods, "Every request should have been accepted")
+	err = conditional.AbortUploadSession("9")
+	assert.NoError(t, err, "Aborting an upload session should not be conditional")
+
+	err = client.IfMatch("2").DeleteFile("5")
+	assert.True(t, IsPreconditionFailed(err), "Delete with a stale ETag should fail")
+}
+
+func TestIfNoneMatch(t *testing.T) {
+	server, client := newTestHandlerClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == "3" {
+			assert.Equal(t, "/folders/7", r.URL.Path, "If-None-Match should only be sent when getting an item")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		if r.URL.Path == "/folders/7/items" {
+			fmt.Fprintln(w, `{"total_count": 0, "entries": [], "offset": 0, "limit": 100}`)
+			return
+		}
+		fmt.Fprintln(w, `{"type": "folder", "id": "7", "etag": "3"}`)
+	}))
+	defer server.Close()
+
+	folder, err := client.GetFolder("7")
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, "3", folder.ETag, "Folder should be decoded")
+
+	folder, err = client.IfNoneMatch(folder.ETag).GetFolder("7")
+	assert.Equal(t, ErrNotModified, err, "Unchanged folder should not be fetched again")
+	assert.Nil(t, folder, "No folder should be returned when it is unchanged")
+
+	folder, err = client.IfNoneMatch("2").GetFolder("7")
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, "3", folder.ETag, "Changed folder should be fetched")
+
+	it := client.IfNoneMatch(folder.ETag).GetFolderItems("7", nil)
+	for it.Next() {
+	}
+	assert.NoError(t, it.Err(), "Listing should not be conditional")
+}
+
+func TestAsUser(t *testing.T) {
+	var asUser []string
+	server, client := newTestHandlerClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		asUser = append(asUser, r.Header.Get("As-User"))
+		fmt.Fprintln(w, `{"type": "user", "id": "1"}`)
+	}))
+	defer server.Close()
+
+	_, err := client.AsUser("9").GetCurrentUser()
+	assert.NoError(t, err, "Function should not return error")
+	_, err = client.GetCurrentUser()
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, []string{"9", ""}, asUser, "Only the returned client should act as the other user")
+}
@@ -0,0 +1,118 @@
+package box
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+)
+
+const (
+	RoleEditor            = "editor"
+	RoleViewer            = "viewer"
+	RolePreviewer         = "previewer"
+	RoleUploader          = "uploader"
+	RolePreviewerUploader = "previewer uploader"
+	RoleViewerUploader    = "viewer uploader"
+	RoleCoOwner           = "co-owner"
+	RoleOwner             = "owner"
+
+	CollaborationStatusAccepted = "accepted"
+	CollaborationStatusPending  = "pending"
+	CollaborationStatusRejected = "rejected"
+)
+
+// GetFolderCollaborations returns the collaborations that give users and
+// groups access to the folder folderID.
+func (c *client) GetFolderCollaborations(folderID string) ([]Collaboration, error) {
+	body, err := c.Get("/folders/" + folderID + "/collaborations")
+	if err != nil {
+		return nil, err
+	}
+	var collection CollaborationCollection
+	err = json.Unmarshal(body, &collection)
+	if err != nil {
+		return nil, err
+	}
+	return collection.Entries, nil
+}
+
+// GetPendingCollaborations returns the invitations to folders that the
+// current user has not yet accepted or rejected.
+func (c *client) GetPendingCollaborations() ([]Collaboration, error) {
+	var collaborations []Collaboration
+	for {
+		body, err := c.Get("/collaborations?status=" + CollaborationStatusPending +
+			"&limit=100&offset=" + strconv.Itoa(len(collaborations)))
+		if err != nil {
+			return nil, err
+		}
+		var collection CollaborationCollection
+		err = json.Unmarshal(body, &collection)
+		if err != nil {
+			return nil, err
+		}
+		collaborations = append(collaborations, collection.Entries...)
+		if len(collection.Entries) == 0 || len(collaborations) >= collection.Count {
+			return collaborations, nil
+		}
+	}
+}
+
+// AddCollaboration gives collaborator access to the folder folderID with
+// role, inviting them by email if they are identified by a login without a
+// Box account.
+func (c *client) AddCollaboration(folderID string, collaborator Collaborator, role string) (*Collaboration, error) {
+	attrJSON, err := json.Marshal(CollaborationAttributes{
+		Item:         ItemReference{Type: TypeFolder, ID: folderID},
+		AccessibleBy: collaborator,
+		Role:         role,
+	})
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.Post("/collaborations", "application/json", bytes.NewReader(attrJSON), false)
+	if err != nil {
+		return nil, err
+	}
+	return decodeCollaboration(body)
+}
+
+func (c *client) SetCollaborationRole(id, role string) (*Collaboration, error) {
+	return c.updateCollaboration(id, CollaborationUpdate{Role: role})
+}
+
+// AcceptCollaboration accepts the pending invitation id for the current user.
+func (c *client) AcceptCollaboration(id string) (*Collaboration, error) {
+	return c.updateCollaboration(id, CollaborationUpdate{Status: CollaborationStatusAccepted})
+}
+
+// RejectCollaboration rejects the pending invitation id for the current user.
+func (c *client) RejectCollaboration(id string) (*Collaboration, error) {
+	return c.updateCollaboration(id, CollaborationUpdate{Status: CollaborationStatusRejected})
+}
+
+func (c *client) DeleteCollaboration(id string) error {
+	_, err := c.Delete("/collaborations/" + id)
+	return err
+}
+
+func (c *client) updateCollaboration(id string, update CollaborationUpdate) (*Collaboration, error) {
+	updateJSON, err := json.Marshal(update)
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.Put("/collaborations/"+id, "application/json", bytes.NewReader(updateJSON), false)
+	if err != nil {
+		return nil, err
+	}
+	return decodeCollaboration(body)
+}
+
+func decodeCollaboration(body []byte) (*Collaboration, error) {
+	var collaboration Collaboration
+	err := json.Unmarshal(body, &collaboration)
+	if err != nil {
+		return nil, err
+	}
+	return &collaboration, nil
+}
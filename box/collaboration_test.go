@@ -0,0 +1,58 @@
+package box
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollaborations(t *testing.T) {
+	var method, path, body string
+	server, client := newTestHandlerClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		method, path, body = r.Method, r.URL.Path, string(data)
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/folders/42/collaborations":
+			fmt.Fprintln(w, `{"total_count": 1, "entries": [{"type": "collaboration", "id": "7", "role": "viewer",
+				"status": "accepted", "accessible_by": {"type": "user", "id": "9", "login": "alice@example.com"}}]}`)
+		case r.Method == "POST" && r.URL.Path == "/collaborations":
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintln(w, `{"type": "collaboration", "id": "8", "role": "editor", "status": "pending"}`)
+		case r.Method == "PUT" && r.URL.Path == "/collaborations/7":
+			fmt.Fprintln(w, `{"type": "collaboration", "id": "7", "role": "editor", "status": "accepted"}`)
+		case r.Method == "DELETE" && r.URL.Path == "/collaborations/7":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	collaborations, err := client.GetFolderCollaborations("42")
+	assert.NoError(t, err, "Function should not return error")
+	assert.Len(t, collaborations, 1, "One collaboration should be listed")
+	assert.Equal(t, "alice@example.com", collaborations[0].AccessibleBy.Login, "Collaborator should be decoded")
+
+	collaboration, err := client.AddCollaboration("42", Collaborator{Type: TypeUser, Login: "bob@example.com"}, RoleEditor)
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, CollaborationStatusPending, collaboration.Status, "Invitation should be pending")
+	assert.Equal(t, `{"item":{"type":"folder","id":"42"},"accessible_by":{"type":"user","login":"bob@example.com"},"role":"editor"}`,
+		body, "Invitation should identify the user by login")
+
+	collaboration, err = client.SetCollaborationRole("7", RoleEditor)
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, RoleEditor, collaboration.Role, "Role should be updated")
+	assert.Equal(t, `{"role":"editor"}`, body, "Only the role should be sent")
+
+	_, err = client.AcceptCollaboration("7")
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, `{"status":"accepted"}`, body, "Only the status should be sent")
+
+	err = client.DeleteCollaboration("7")
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, "DELETE", method, "Removing should be a DELETE")
+	assert.Equal(t, "/collaborations/7", path, "Removing should delete the collaboration")
+}
@@ -0,0 +1,125 @@
+package box
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+// CollectionTypeFavorites is the type of the collection holding the user's
+// favorites.
+const CollectionTypeFavorites = "favorites"
+
+// collectionMembership is the part of an item listing the collections it
+// belongs to. Only their IDs are needed to change them.
+type collectionMembership struct {
+	Collections []collectionRef `json:"collections"`
+}
+
+type collectionRef struct {
+	ID string `json:"id"`
+}
+
+func (c *client) GetCollections() ([]ItemCollection, error) {
+	body, err := c.Get("/collections")
+	if err != nil {
+		return nil, err
+	}
+	var collection struct {
+		Entries []ItemCollection `json:"entries"`
+	}
+	err = json.Unmarshal(body, &collection)
+	if err != nil {
+		return nil, err
+	}
+	return collection.Entries, nil
+}
+
+// GetFavorites returns the collection holding the current user's favorites.
+func (c *client) GetFavorites() (*ItemCollection, error) {
+	collections, err := c.GetCollections()
+	if err != nil {
+		return nil, err
+	}
+	for _, collection := range collections {
+		if collection.CollectionType == CollectionTypeFavorites {
+			return &collection, nil
+		}
+	}
+	return nil, errors.New("favorites collection not found")
+}
+
+// GetCollectionItems returns an iterator over the pages of items in the
+// collection id. Collections are always paged with offsets.
+func (c *client) GetCollectionItems(id string, opts *ItemsOptions) *ItemIterator {
+	it := c.newItemIterator(id, "/collections/"+id+"/items", opts)
+	it.opts.UseOffset = true
+	return it
+}
+
+// AddToCollection adds the item itemID of type itemType, one of TypeFile,
+// TypeFolder or TypeWebLink, to the collection collectionID.
+func (c *client) AddToCollection(collectionID, itemType, itemID string) error {
+	return c.updateCollections(itemType, itemID, func(ids []collectionRef) []collectionRef {
+		for _, id := range ids {
+			if id.ID == collectionID {
+				return ids
+			}
+		}
+		return append(ids, collectionRef{ID: collectionID})
+	})
+}
+
+// RemoveFromCollection removes the item itemID of type itemType from the
+// collection collectionID, as for AddToCollection.
+func (c *client) RemoveFromCollection(collectionID, itemType, itemID string) error {
+	return c.updateCollections(itemType, itemID, func(ids []collectionRef) []collectionRef {
+		kept := []collectionRef{}
+		for _, id := range ids {
+			if id.ID != collectionID {
+				kept = append(kept, id)
+			}
+		}
+		return kept
+	})
+}
+
+// updateCollections replaces the collections the item belongs to with the
+// result of update, since Box only allows setting all of them at once.
+func (c *client) updateCollections(itemType, itemID string, update func([]collectionRef) []collectionRef) error {
+	endpointPath, err := itemPath(itemType, itemID)
+	if err != nil {
+		return err
+	}
+
+	body, err := c.Get(endpointPath + "?fields=collections")
+	if err != nil {
+		return err
+	}
+	var membership collectionMembership
+	err = json.Unmarshal(body, &membership)
+	if err != nil {
+		return err
+	}
+
+	membership.Collections = update(membership.Collections)
+	updateJSON, err := json.Marshal(membership)
+	if err != nil {
+		return err
+	}
+	_, err = c.Put(endpointPath+"?fields=collections", "application/json", bytes.NewReader(updateJSON), false)
+	return err
+}
+
+// itemPath returns the endpoint of the item id of type itemType.
+func itemPath(itemType, id string) (string, error) {
+	switch itemType {
+	case TypeFile:
+		return "/files/" + id, nil
+	case TypeFolder:
+		return "/folders/" + id, nil
+	case TypeWebLink:
+		return "/web_links/" + id, nil
+	}
+	return "", errors.New("unknown item type " + itemType)
+}
@@ -0,0 +1,53 @@
+package box
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFavorites(t *testing.T) {
+	var body string
+	server, client := newTestHandlerClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/collections":
+			fmt.Fprintln(w, `{"total_count": 1, "entries": [{"type": "collection", "id": "11", "name": "Favorites", "collection_type": "favorites"}]}`)
+		case r.Method == "GET" && r.URL.Path == "/collections/11/items":
+			assert.Equal(t, "0", r.URL.Query().Get("offset"), "Collections should be paged with offsets")
+			fmt.Fprintln(w, `{"total_count": 2, "entries": [{"type": "file", "id": "1"}, {"type": "web_link", "id": "2"}]}`)
+		case r.Method == "GET" && r.URL.Path == "/files/1":
+			fmt.Fprintln(w, `{"type": "file", "id": "1", "collections": [{"type": "collection", "id": "12", "name": "Other"}]}`)
+		case r.Method == "PUT" && r.URL.Path == "/files/1":
+			body = string(data)
+			fmt.Fprintln(w, `{"type": "file", "id": "1"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	favorites, err := client.GetFavorites()
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, "11", favorites.ID, "Favorites should be found by type")
+
+	it := client.GetCollectionItems(favorites.ID, nil)
+	assert.True(t, it.Next(), "First page should be returned")
+	assert.NoError(t, it.Err(), "Function should not return error")
+	assert.Len(t, it.Page().Files, 1, "Files should be listed")
+	assert.Len(t, it.Page().WebLinks, 1, "Web links should be listed")
+
+	err = client.AddToCollection(favorites.ID, TypeFile, "1")
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, `{"collections":[{"id":"12"},{"id":"11"}]}`, body, "Existing collections should be kept")
+
+	err = client.RemoveFromCollection("12", TypeFile, "1")
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, `{"collections":[]}`, body, "Removed collection should be left out")
+
+	err = client.AddToCollection(favorites.ID, "group", "1")
+	assert.Error(t, err, "Unknown item type should be rejected")
+}
@@ -0,0 +1,77 @@
+package box
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+)
+
+// GetFileComments returns the comments on the file fileID, including replies,
+// oldest first.
+func (c *client) GetFileComments(fileID string) ([]Comment, error) {
+	var comments []Comment
+	for {
+		body, err := c.Get("/files/" + fileID + "/comments?limit=100&offset=" + strconv.Itoa(len(comments)))
+		if err != nil {
+			return nil, err
+		}
+		var collection CommentCollection
+		err = json.Unmarshal(body, &collection)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, collection.Entries...)
+		if len(collection.Entries) == 0 || len(comments) >= collection.Count {
+			return comments, nil
+		}
+	}
+}
+
+func (c *client) AddComment(fileID, message string) (*Comment, error) {
+	return c.postComment(ItemReference{Type: TypeFile, ID: fileID}, message)
+}
+
+// ReplyToComment adds message as a reply to the comment commentID.
+func (c *client) ReplyToComment(commentID, message string) (*Comment, error) {
+	return c.postComment(ItemReference{Type: TypeComment, ID: commentID}, message)
+}
+
+// UpdateComment replaces the text of the comment id with message. Only the
+// author of a comment can edit it.
+func (c *client) UpdateComment(id, message string) (*Comment, error) {
+	attrJSON, err := json.Marshal(CommentAttributes{Message: message})
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.Put("/comments/"+id, "application/json", bytes.NewReader(attrJSON), false)
+	if err != nil {
+		return nil, err
+	}
+	return decodeComment(body)
+}
+
+func (c *client) DeleteComment(id string) error {
+	_, err := c.Delete("/comments/" + id)
+	return err
+}
+
+func (c *client) postComment(item ItemReference, message string) (*Comment, error) {
+	attrJSON, err := json.Marshal(CommentAttributes{Message: message, Item: &item})
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.Post("/comments", "application/json", bytes.NewReader(attrJSON), false)
+	if err != nil {
+		return nil, err
+	}
+	return decodeComment(body)
+}
+
+func decodeComment(body []byte) (*Comment, error) {
+	var comment Comment
+	err := json.Unmarshal(body, &comment)
+	if err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
@@ -0,0 +1,62 @@
+package box
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComments(t *testing.T) {
+	var method, path, body string
+	server, client := newTestHandlerClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		method, path, body = r.Method, r.URL.Path, string(data)
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/files/42/comments":
+			if r.URL.Query().Get("offset") == "0" {
+				fmt.Fprintln(w, `{"total_count": 2, "offset": 0, "entries": [{"type": "comment", "id": "1", "message": "First"}]}`)
+			} else {
+				fmt.Fprintln(w, `{"total_count": 2, "offset": 1, "entries": [{"type": "comment", "id": "2", "message": "Second",
+					"is_reply_comment": true, "created_by": {"type": "user", "id": "9", "login": "alice@example.com"}}]}`)
+			}
+		case r.Method == "POST" && r.URL.Path == "/comments":
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintln(w, `{"type": "comment", "id": "3", "message": "Looks good"}`)
+		case r.Method == "PUT" && r.URL.Path == "/comments/3":
+			fmt.Fprintln(w, `{"type": "comment", "id": "3", "message": "Looks great"}`)
+		case r.Method == "DELETE" && r.URL.Path == "/comments/3":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	comments, err := client.GetFileComments("42")
+	assert.NoError(t, err, "Function should not return error")
+	assert.Len(t, comments, 2, "Comments from every page should be listed")
+	assert.True(t, comments[1].IsReplyComment, "Reply flag should be decoded")
+	assert.Equal(t, "alice@example.com", comments[1].CreatedBy.Login, "Author should be decoded")
+
+	comment, err := client.AddComment("42", "Looks good")
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, "3", comment.ID, "New comment should be returned")
+	assert.Equal(t, `{"message":"Looks good","item":{"type":"file","id":"42"}}`, body, "Comment should be on the file")
+
+	_, err = client.ReplyToComment("1", "Looks good")
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, `{"message":"Looks good","item":{"type":"comment","id":"1"}}`, body, "Reply should be on the comment")
+
+	comment, err = client.UpdateComment("3", "Looks great")
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, "Looks great", comment.Message, "Comment should be updated")
+	assert.Equal(t, `{"message":"Looks great"}`, body, "Only the message should be sent")
+
+	err = client.DeleteComment("3")
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, "DELETE", method, "Removing should be a DELETE")
+	assert.Equal(t, "/comments/3", path, "Removing should delete the comment")
+}
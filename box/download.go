@@ -0,0 +1,315 @@
+package box
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// PartialDownloadSuffix is appended to the name of the temporary file a
+	// download is written to before it is moved into place.
+	PartialDownloadSuffix = ".boxpart"
+
+	maxDownloadAttempts  = 5
+	defaultDownloadDelay = time.Second
+)
+
+var errDownloadNotReady = errors.New("file is not ready for download")
+
+// PartialDownloadPath returns the path of the temporary file used while
+// downloading to destPath. It lives in the same directory as destPath so that
+// the finished download can be renamed into place atomically.
+func PartialDownloadPath(destPath string) string {
+	dir, name := filepath.Split(destPath)
+	return filepath.Join(dir, "."+name+PartialDownloadSuffix)
+}
+
+// IsPartialDownload reports whether filePath is the temporary file of an
+// unfinished download.
+func IsPartialDownload(filePath string) bool {
+	name := filepath.Base(filePath)
+	return strings.HasPrefix(name, ".") && strings.HasSuffix(name, PartialDownloadSuffix)
+}
+
+// DownloadFile downloads the file id to destPath. The content is written to a
+// temporary file next to destPath, which is resumed with range requests if
+// the download is interrupted, and is only renamed to destPath once its sha1
+// hash matches the one Box reports. destPath is left untouched on failure.
+func (c *client) DownloadFile(id, destPath string) error {
+	file, err := c.GetFile(id)
+	if err != nil {
+		return err
+	}
+
+	tmpPath := PartialDownloadPath(destPath)
+	out, err := os.OpenFile(tmpPath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+
+	err = c.downloadToPartialFile(id, int64(file.Size), out)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	if file.SHA1 != "" {
+		sum, err := fileSHA1(tmpPath)
+		if err != nil {
+			return err
+		}
+		if sum != file.SHA1 {
+			os.Remove(tmpPath)
+			return fmt.Errorf("downloaded file %s has sha1 %s, expected %s", id, sum, file.SHA1)
+		}
+	}
+
+	return os.Rename(tmpPath, destPath)
+}
+
+// downloadToPartialFile downloads the file id into out, resuming from the
+// bytes out already contains. Interrupted transfers are retried from where
+// they stopped.
+func (c *client) downloadToPartialFile(id string, size int64, out *os.File) error {
+	var err error
+	for attempt := 1; attempt <= maxDownloadAttempts; attempt++ {
+		var offset int64
+		offset, err = out.Seek(0, io.SeekEnd)
+		if err != nil {
+			return err
+		}
+		if offset > size {
+			if err := out.Truncate(0); err != nil {
+				return err
+			}
+			offset = 0
+		}
+		if offset == size && size > 0 {
+			return nil
+		}
+
+		var retry bool
+		retry, err = c.downloadRange(id, offset, out)
+		if err == nil || !retry {
+			return err
+		}
+		if err == errDownloadNotReady {
+			if err := c.sleep(defaultDownloadDelay); err != nil {
+				return err
+			}
+		}
+	}
+	return err
+}
+
+// downloadRange requests the content of the file id starting at offset and
+// writes it to out. If the server ignores the range, out is truncated and the
+// whole file is written. The returned bool reports whether a failed download
+// is worth retrying.
+func (c *client) downloadRange(id string, offset int64, out *os.File) (bool, error) {
+	req, err := c.newContentRequest(contentPath(id), offset, -1)
+	if err != nil {
+		return false, err
+	}
+
+	r, err := c.send(req)
+	if err != nil {
+		return true, err
+	}
+	defer r.Body.Close()
+
+	switch r.StatusCode {
+	case http.StatusPartialContent:
+		if _, err := out.Seek(offset, io.SeekStart); err != nil {
+			return false, err
+		}
+	case http.StatusOK:
+		if err := out.Truncate(0); err != nil {
+			return false, err
+		}
+		if _, err := out.Seek(0, io.SeekStart); err != nil {
+			return false, err
+		}
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The partial file is not a prefix of the current content, so start
+		// over.
+		if err := out.Truncate(0); err != nil {
+			return false, err
+		}
+		return true, errors.New("range not satisfiable: " + r.Status)
+	case http.StatusAccepted:
+		return true, errDownloadNotReady
+	default:
+		_, err := handleResponse(r)
+		if err == nil {
+			err = errors.New("unexpected download response: " + r.Status)
+		}
+		return false, err
+	}
+
+	_, err = io.Copy(out, r.Body)
+	return true, err
+}
+
+// newContentRequest returns a request for length bytes of the content at
+// contentPath starting at offset, or for everything from offset on if length is negative.
+func (c *client) newContentRequest(contentPath string, offset, length int64) (*http.Request, error) {
+	req, err := c.newRequest("GET", c.endpointURL(contentPath), nil)
+	if err != nil {
+		return nil, err
+	}
+	if length > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+	} else if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	return req, nil
+}
+
+func contentPath(id string) string {
+	return "/files/" + id + "/content"
+}
+
+func fileSHA1(filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha1.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// DownloadFileTo writes the content of the file id to w.
+func (c *client) DownloadFileTo(id string, w io.Writer) error {
+	_, err := c.DownloadFileRange(id, w, 0, -1)
+	return err
+}
+
+// DownloadFileRange writes length bytes of the file id starting at offset to
+// w, or everything from offset to the end of the file if length is negative.
+// It returns the number of bytes written, and io.EOF if offset is past the end
+// of the file.
+func (c *client) DownloadFileRange(id string, w io.Writer, offset, length int64) (int64, error) {
+	return c.downloadContentRange(contentPath(id), w, offset, length)
+}
+
+func (c *client) downloadContentRange(contentPath string, w io.Writer, offset, length int64) (int64, error) {
+	if length == 0 {
+		return 0, nil
+	}
+
+	req, err := c.newContentRequest(contentPath, offset, length)
+	if err != nil {
+		return 0, err
+	}
+
+	r, err := c.send(req)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Body.Close()
+
+	var body io.Reader = r.Body
+	switch r.StatusCode {
+	case http.StatusPartialContent:
+	case http.StatusOK:
+		// The server ignored the range, so skip to offset ourselves.
+		if _, err := io.CopyN(ioutil.Discard, r.Body, offset); err == io.EOF {
+			return 0, io.EOF
+		} else if err != nil {
+			return 0, err
+		}
+	case http.StatusRequestedRangeNotSatisfiable:
+		return 0, io.EOF
+	case http.StatusAccepted:
+		return 0, errDownloadNotReady
+	default:
+		_, err := handleResponse(r)
+		if err == nil {
+			err = errors.New("unexpected download response: " + r.Status)
+		}
+		return 0, err
+	}
+
+	if length > 0 {
+		body = io.LimitReader(body, length)
+	}
+	return io.Copy(w, body)
+}
+
+// FileReader provides random access to the content of a remote file without
+// downloading all of it.
+type FileReader struct {
+	client Client
+	id     string
+	size   int64
+}
+
+// NewFileReader returns a FileReader for file, which must have its ID and Size
+// set.
+func NewFileReader(client Client, file *File) *FileReader {
+	return &FileReader{
+		client: client,
+		id:     file.ID,
+		size:   int64(file.Size),
+	}
+}
+
+// Size returns the size of the file in bytes.
+func (f *FileReader) Size() int64 {
+	return f.size
+}
+
+// ReadAt implements io.ReaderAt by requesting the byte range
+// [off, off+len(p)) of the file.
+func (f *FileReader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("box.FileReader.ReadAt: negative offset")
+	}
+	if off >= f.size {
+		return 0, io.EOF
+	}
+
+	length := int64(len(p))
+	if f.size-off < length {
+		length = f.size - off
+	}
+
+	buf := sliceWriter{p: p[:length]}
+	n, err := f.client.DownloadFileRange(f.id, &buf, off, length)
+	if err == nil && n < int64(len(p)) {
+		err = io.EOF
+	}
+	return int(n), err
+}
+
+// sliceWriter writes into a fixed-size byte slice.
+type sliceWriter struct {
+	p []byte
+	n int
+}
+
+func (w *sliceWriter) Write(b []byte) (int, error) {
+	n := copy(w.p[w.n:], b)
+	w.n += n
+	if n < len(b) {
+		return n, io.ErrShortWrite
+	}
+	return n, nil
+}
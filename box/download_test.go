@@ -0,0 +1,130 @@
+package box
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testDownloadContent = "The quick brown fox jumps over the lazy dog"
+
+func newTestDownloadClient(content, sha string, ranges *[]string) (*httptest.Server, *client) {
+	server, client := newTestHandlerClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/files/1234":
+			fmt.Fprintf(w, `{"id": "1234", "size": %d, "sha1": "%s"}`, len(content), sha)
+		case "/files/1234/content":
+			rangeHeader := r.Header.Get("Range")
+			*ranges = append(*ranges, rangeHeader)
+			if rangeHeader == "" {
+				fmt.Fprint(w, content)
+				return
+			}
+			bounds := strings.Split(strings.TrimPrefix(rangeHeader, "bytes="), "-")
+			start, _ := strconv.Atoi(bounds[0])
+			end := len(content) - 1
+			if bounds[1] != "" {
+				end, _ = strconv.Atoi(bounds[1])
+			}
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(content)))
+			w.WriteHeader(http.StatusPartialContent)
+			fmt.Fprint(w, content[start:end+1])
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return server, client
+}
+
+func TestDownloadFileResumesPartialDownload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "boxtest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var ranges []string
+	server, client := newTestDownloadClient(testDownloadContent,
+		fmt.Sprintf("%x", sha1.Sum([]byte(testDownloadContent))), &ranges)
+	defer server.Close()
+
+	destPath := filepath.Join(dir, "fox.txt")
+	err = ioutil.WriteFile(PartialDownloadPath(destPath), []byte(testDownloadContent[:10]), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = client.DownloadFile("1234", destPath)
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, []string{"bytes=10-"}, ranges, "Download should resume at byte 10")
+
+	data, _ := ioutil.ReadFile(destPath)
+	assert.Equal(t, testDownloadContent, string(data), "Downloaded content should match")
+	_, err = os.Stat(PartialDownloadPath(destPath))
+	assert.True(t, os.IsNotExist(err), "Partial download should be renamed")
+}
+
+func TestDownloadFileChecksumMismatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "boxtest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var ranges []string
+	server, client := newTestDownloadClient(testDownloadContent, "0000", &ranges)
+	defer server.Close()
+
+	destPath := filepath.Join(dir, "fox.txt")
+	err = ioutil.WriteFile(destPath, []byte("good local copy"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = client.DownloadFile("1234", destPath)
+	assert.Error(t, err, "Function should return error")
+
+	data, _ := ioutil.ReadFile(destPath)
+	assert.Equal(t, "good local copy", string(data), "Local copy should be untouched")
+	assert.False(t, IsPartialDownload(destPath), "Destination is not a partial download")
+	assert.True(t, IsPartialDownload(PartialDownloadPath(destPath)), "Temporary file is a partial download")
+}
+
+func TestFileReaderReadAt(t *testing.T) {
+	var ranges []string
+	server, client := newTestDownloadClient(testDownloadContent, "", &ranges)
+	defer server.Close()
+
+	reader := NewFileReader(client, &File{ID: "1234", Size: len(testDownloadContent)})
+
+	p := make([]byte, 5)
+	n, err := reader.ReadAt(p, 4)
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, "quick", string(p[:n]), "Should read bytes 4-8")
+
+	n, err = reader.ReadAt(p, int64(len(testDownloadContent)-3))
+	assert.Equal(t, io.EOF, err, "Reading past the end should return io.EOF")
+	assert.Equal(t, "dog", string(p[:n]), "Should read the last 3 bytes")
+
+	n, err = reader.ReadAt(p, int64(len(testDownloadContent)))
+	assert.Equal(t, io.EOF, err, "Reading at the end should return io.EOF")
+	assert.Equal(t, 0, n, "Should read nothing")
+
+	assert.Equal(t, []string{"bytes=4-8", "bytes=40-42"}, ranges, "Only the needed ranges should be requested")
+
+	var buf bytes.Buffer
+	err = client.DownloadFileTo("1234", &buf)
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, testDownloadContent, buf.String(), "Should download the whole file")
+}
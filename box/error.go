@@ -0,0 +1,166 @@
+package box
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	ErrorCodeItemNameInUse         = "item_name_in_use"
+	ErrorCodeItemNameInvalid       = "item_name_invalid"
+	ErrorCodeNotFound              = "not_found"
+	ErrorCodeTrashed               = "trashed"
+	ErrorCodePreconditionFailed    = "precondition_failed"
+	ErrorCodeRateLimitExceeded     = "rate_limit_exceeded"
+	ErrorCodeStorageLimitExceeded  = "storage_limit_exceeded"
+	ErrorCodeFileSizeLimitExceeded = "file_size_limit_exceeded"
+	ErrorCodeAccessDenied          = "access_denied_insufficient_permissions"
+)
+
+// ErrNotModified is returned by GetFile and GetFolder of a Client created by
+// IfNoneMatch when the item still has the given ETag.
+var ErrNotModified = errors.New("box: item not modified")
+
+// APIError is returned by Client methods when Box responds with an error
+// status.
+type APIError struct {
+	StatusCode  int             `json:"status"`       // The HTTP status code of the response.
+	Code        string          `json:"code"`         // Box's error code, e.g. "item_name_in_use".
+	Message     string          `json:"message"`      // A human readable description of the error.
+	RequestID   string          `json:"request_id"`   // Identifies the request when contacting Box support.
+	HelpURL     string          `json:"help_url"`     // A link to documentation about the error.
+	ContextInfo json.RawMessage `json:"context_info"` // Additional error-specific details.
+	RetryAfter  time.Duration   `json:"-"`            // How long to wait before retrying, from the Retry-After header.
+	Body        []byte          `json:"-"`            // The raw response body.
+}
+
+// ConflictItem is an existing item that caused an item_name_in_use error.
+type ConflictItem struct {
+	Type       string `json:"type"`
+	ID         string `json:"id"`
+	SequenceID string `json:"sequence_id"`
+	ETag       string `json:"etag"`
+	SHA1       string `json:"sha1"`
+	Name       string `json:"name"`
+}
+
+func newAPIError(r *http.Response, body []byte) *APIError {
+	apiErr := &APIError{}
+	// Not every error response has a JSON body, in which case only the
+	// status is known.
+	json.Unmarshal(body, apiErr)
+	apiErr.StatusCode = r.StatusCode
+	apiErr.Body = body
+	if seconds, err := strconv.Atoi(r.Header.Get("Retry-After")); err == nil {
+		apiErr.RetryAfter = time.Duration(seconds) * time.Second
+	}
+	return apiErr
+}
+
+func (e *APIError) Error() string {
+	msg := strconv.Itoa(e.StatusCode) + " " + http.StatusText(e.StatusCode)
+	if e.Code != "" {
+		msg += " -- " + e.Code
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	} else if e.Code == "" && len(e.Body) > 0 {
+		msg += " -- " + string(e.Body)
+	}
+	if e.RequestID != "" {
+		msg += " (request ID " + e.RequestID + ")"
+	}
+	return msg
+}
+
+// Conflicts returns the existing items listed in the error's context info.
+// Box reports a single conflicting item for uploads and a list of them for
+// other operations; both are returned as a slice.
+func (e *APIError) Conflicts() []ConflictItem {
+	var info struct {
+		Conflicts json.RawMessage `json:"conflicts"`
+	}
+	if err := json.Unmarshal(e.ContextInfo, &info); err != nil || len(info.Conflicts) == 0 {
+		return nil
+	}
+
+	var conflicts []ConflictItem
+	if err := json.Unmarshal(info.Conflicts, &conflicts); err == nil {
+		return conflicts
+	}
+	var conflict ConflictItem
+	if err := json.Unmarshal(info.Conflicts, &conflict); err == nil {
+		return []ConflictItem{conflict}
+	}
+	return nil
+}
+
+// IsNotFound reports whether err is a Box error caused by a missing item.
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsConflict reports whether err is a Box error caused by a conflict with an
+// existing item.
+func IsConflict(err error) bool {
+	return hasStatus(err, http.StatusConflict)
+}
+
+// IsItemNameInUse reports whether err was caused by an item with the same name
+// already existing in the destination folder.
+func IsItemNameInUse(err error) bool {
+	apiErr, ok := err.(*APIError)
+	return ok && apiErr.Code == ErrorCodeItemNameInUse
+}
+
+// IsItemNameInvalid reports whether err was caused by a name Box does not
+// allow, e.g. one ending with a space.
+func IsItemNameInvalid(err error) bool {
+	apiErr, ok := err.(*APIError)
+	return ok && apiErr.Code == ErrorCodeItemNameInvalid
+}
+
+// IsQuotaExceeded reports whether err was caused by an upload that does not
+// fit in the account's storage or exceeds its maximum file size.
+func IsQuotaExceeded(err error) bool {
+	apiErr, ok := err.(*APIError)
+	return ok && (apiErr.Code == ErrorCodeStorageLimitExceeded || apiErr.Code == ErrorCodeFileSizeLimitExceeded)
+}
+
+// IsPreconditionFailed reports whether err is a Box error caused by a failed
+// If-Match or If-None-Match precondition.
+func IsPreconditionFailed(err error) bool {
+	return hasStatus(err, http.StatusPreconditionFailed)
+}
+
+// IsRateLimited reports whether err is a Box error caused by exceeding the
+// rate limit.
+func IsRateLimited(err error) bool {
+	return hasStatus(err, http.StatusTooManyRequests)
+}
+
+// IsUnauthorized reports whether err is a Box error caused by an invalid or
+// expired access token.
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized)
+}
+
+// IsForbidden reports whether err is a Box error caused by insufficient
+// permissions or exhausted quota.
+func IsForbidden(err error) bool {
+	return hasStatus(err, http.StatusForbidden)
+}
+
+// IsServerError reports whether err is a Box error with a 5xx status.
+func IsServerError(err error) bool {
+	apiErr, ok := err.(*APIError)
+	return ok && apiErr.StatusCode >= 500
+}
+
+func hasStatus(err error, status int) bool {
+	apiErr, ok := err.(*APIError)
+	return ok && apiErr.StatusCode == status
+}
@@ -0,0 +1,51 @@
+package box
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIError(t *testing.T) {
+	server, client := newTestHandlerClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/folders":
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprintln(w, `{
+				"type": "error",
+				"status": 409,
+				"code": "item_name_in_use",
+				"context_info": {"conflicts": [{"type": "folder", "id": "5678", "name": "Docs"}]},
+				"message": "Item with the same name already exists",
+				"request_id": "abcdef"
+			}`)
+		case "/users/me":
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	_, err := client.CreateFolder("Docs", "0")
+	assert.True(t, IsConflict(err), "Error should be a conflict")
+	assert.True(t, IsItemNameInUse(err), "Error should be item_name_in_use")
+	assert.False(t, IsNotFound(err), "Error should not be not found")
+	apiErr := err.(*APIError)
+	assert.Equal(t, "abcdef", apiErr.RequestID, "Request ID should be \"abcdef\"")
+	assert.Equal(t, []ConflictItem{{Type: "folder", ID: "5678", Name: "Docs"}}, apiErr.Conflicts(),
+		"Conflicting folder should be reported")
+	assert.Equal(t, "409 Conflict -- item_name_in_use: Item with the same name already exists (request ID abcdef)",
+		err.Error(), "Error message should include code, message and request ID")
+
+	_, err = client.GetCurrentUser()
+	assert.True(t, IsRateLimited(err), "Error should be rate limited")
+	assert.Equal(t, 7*time.Second, err.(*APIError).RetryAfter, "Retry-After should be 7 seconds")
+
+	_, err = client.GetFile("1234")
+	assert.True(t, IsNotFound(err), "Error should be not found")
+}
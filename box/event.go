@@ -76,48 +76,64 @@ func (c *client) GetLongPollURL() (string, error) {
 	return resp.Entries[0].URL, nil
 }
 
+// GetEventStream long polls longPollURL and sends every new event on the
+// returned event channel until quit is closed, the client's context is done or
+// an error occurs, in which case the error is sent on the error channel.
 func (c *client) GetEventStream(longPollURL, streamPosition string, quit <-chan struct{}) (<-chan Event, <-chan error, error) {
 	eventStream := make(chan Event)
-	errorStream := make(chan error)
+	errorStream := make(chan error, 1)
 
-	if streamPosition == "now" || streamPosition == "" {
-		collection, err := c.GetEvents("now")
+	if streamPosition == StreamPositionNow || streamPosition == "" {
+		collection, err := c.GetEvents(StreamPositionNow)
 		if err != nil {
 			return nil, nil, err
 		}
 		streamPosition = strconv.Itoa(collection.NextStreamPosition)
 	}
 
+	done := c.requestContext().Done()
+
 	go func() {
 		for {
 			select {
 			case <-quit:
-				break
+				return
+			case <-done:
+				return
 			default:
 			}
 
-			body, err := c.GetByURL(longPollURL + "&streamPosition=" + streamPosition)
+			body, err := c.GetByURL(longPollURL + "&stream_position=" + streamPosition)
 			if err != nil {
 				errorStream <- err
-				break
+				return
 			}
 			var resp LongPollResponse
 			err = json.Unmarshal(body, &resp)
 			if err != nil {
 				errorStream <- err
-				break
+				return
+			}
+			if resp.Message == "reconnect" {
+				continue
 			}
 			if resp.Message != "new_change" {
-				errorStream <- err
-				break
+				errorStream <- errors.New("Unexpected long poll message: " + resp.Message)
+				return
 			}
 			collection, err := c.GetEvents(streamPosition)
 			if err != nil {
 				errorStream <- err
-				break
+				return
 			}
 			for _, event := range collection.Entries {
-				eventStream <- event
+				select {
+				case eventStream <- event:
+				case <-quit:
+					return
+				case <-done:
+					return
+				}
 			}
 			streamPosition = strconv.Itoa(collection.NextStreamPosition)
 		}
@@ -125,3 +141,39 @@ func (c *client) GetEventStream(longPollURL, streamPosition string, quit <-chan
 
 	return eventStream, errorStream, nil
 }
+
+// SourceFile returns the file the event is about, or nil if its source is not
+// a file.
+func (e *Event) SourceFile() *File {
+	if e.sourceType() != TypeFile {
+		return nil
+	}
+	var file File
+	if err := json.Unmarshal(e.Source, &file); err != nil {
+		return nil
+	}
+	return &file
+}
+
+// SourceFolder returns the folder the event is about, or nil if its source is
+// not a folder.
+func (e *Event) SourceFolder() *Folder {
+	if e.sourceType() != TypeFolder {
+		return nil
+	}
+	var folder Folder
+	if err := json.Unmarshal(e.Source, &folder); err != nil {
+		return nil
+	}
+	return &folder
+}
+
+func (e *Event) sourceType() string {
+	var source struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(e.Source, &source); err != nil {
+		return ""
+	}
+	return source.Type
+}
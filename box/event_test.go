@@ -0,0 +1,27 @@
+package box
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventSource(t *testing.T) {
+	event := Event{
+		EventType: EventTypeItemRename,
+		Source:    json.RawMessage(`{"type": "file", "id": "1234", "name": "renamed.txt", "parent": {"type": "folder", "id": "42"}}`),
+	}
+	file := event.SourceFile()
+	assert.NotNil(t, file, "Source should be a file")
+	assert.Equal(t, "renamed.txt", file.Name, "Name should be \"renamed.txt\"")
+	assert.Equal(t, "42", file.Parent.ID, "Parent should be \"42\"")
+	assert.Nil(t, event.SourceFolder(), "Source should not be a folder")
+
+	event.Source = json.RawMessage(`{"type": "folder", "id": "5678", "name": "docs"}`)
+	assert.Nil(t, event.SourceFile(), "Source should not be a file")
+	assert.Equal(t, "docs", event.SourceFolder().Name, "Name should be \"docs\"")
+
+	event.Source = nil
+	assert.Nil(t, event.SourceFile(), "Missing source should not be a file")
+}
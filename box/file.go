@@ -11,7 +11,7 @@ import (
 )
 
 func (c *client) GetFile(id string) (*File, error) {
-	body, err := c.Get("/files/" + id)
+	body, err := c.getItem("/files/" + id)
 	if err != nil {
 		return nil, err
 	}
@@ -23,57 +23,74 @@ func (c *client) GetFile(id string) (*File, error) {
 	return &file, nil
 }
 
-func (c *client) DownloadFile(id, destPath string) error {
-	out, err := os.Create(destPath)
+func (c *client) UploadFile(srcPath, parentID string) (*File, error) {
+	file, err := os.Open(srcPath)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer out.Close()
+	defer file.Close()
 
-	r, err := c.client.Get(c.endpointURL("/files/" + id + "/content"))
+	fi, err := file.Stat()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer r.Body.Close()
-
-	_, err = io.Copy(out, r.Body)
 
-	return nil
+	return c.UploadReader(file, path.Base(srcPath), parentID, fi.Size())
 }
 
-func (c *client) UploadFile(srcPath, parentID string) (*File, error) {
-	filename := path.Base(srcPath)
-	file, err := os.Open(srcPath)
-	if err != nil {
-		return nil, err
+// UploadReader uploads the content read from r as a new file called name in
+// the folder parentID. size is the number of bytes r will return, or -1 if it
+// is not known in advance.
+func (c *client) UploadReader(r io.Reader, name, parentID string, size int64) (*File, error) {
+	if size >= c.chunkedUploadThreshold {
+		session, err := c.CreateUploadSession(parentID, name, size)
+		if err != nil {
+			return nil, err
+		}
+		return c.uploadChunked(session, r, size)
 	}
 
-	fileBody := &bytes.Buffer{}
-	writer := multipart.NewWriter(fileBody)
-
-	attr, err := attributesJSON(filename, parentID)
+	attr, err := attributesJSON(name, parentID)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := writer.WriteField("attributes", string(attr)); err != nil {
+	respBody, err := c.uploadMultipart("/files/content", attr, name, r, size)
+	if err != nil {
 		return nil, err
 	}
 
-	filePart, err := writer.CreateFormFile("file", filename)
+	return handleUploadResponse(respBody)
+}
+
+func (c *client) UploadFileVersion(fileID, srcPath string) (*File, error) {
+	file, err := os.Open(srcPath)
 	if err != nil {
 		return nil, err
 	}
+	defer file.Close()
 
-	if _, err := io.Copy(filePart, file); err != nil {
+	fi, err := file.Stat()
+	if err != nil {
 		return nil, err
 	}
 
-	if err := writer.Close(); err != nil {
-		return nil, err
+	return c.UploadFileVersionReader(fileID, file, path.Base(srcPath), fi.Size())
+}
+
+// UploadFileVersionReader uploads the content read from r as a new version of
+// the file fileID. size is the number of bytes r will return, or -1 if it is
+// not known in advance.
+func (c *client) UploadFileVersionReader(fileID string, r io.Reader, name string, size int64) (*File, error) {
+	if size >= c.chunkedUploadThreshold {
+		session, err := c.CreateUploadSessionVersion(fileID, size)
+		if err != nil {
+			return nil, err
+		}
+		return c.uploadChunked(session, r, size)
 	}
 
-	respBody, err := c.Post("/files/content", writer.FormDataContentType(), fileBody, true)
+	respBody, err := c.uploadMultipart("/files/"+fileID+"/content", nil, name, r, size)
 	if err != nil {
 		return nil, err
 	}
@@ -81,40 +98,166 @@ func (c *client) UploadFile(srcPath, parentID string) (*File, error) {
 	return handleUploadResponse(respBody)
 }
 
-func (c *client) UploadFileVersion(fileID, srcPath string) (*File, error) {
-	filename := path.Base(srcPath)
-	file, err := os.Open(srcPath)
+// PreflightUpload checks whether a file called name of size bytes can be
+// uploaded to the folder parentID without sending its content. It returns the
+// error the upload would fail with, which satisfies IsItemNameInUse if the
+// name is taken, IsQuotaExceeded if the file does not fit in the account or
+// IsItemNameInvalid if the name is not allowed.
+func (c *client) PreflightUpload(name, parentID string, size int64) error {
+	return c.preflight("/files/content", PreflightAttributes{
+		Name:   name,
+		Parent: &Parent{ID: parentID},
+		Size:   size,
+	})
+}
+
+// PreflightUploadVersion checks whether a new version of size bytes can be
+// uploaded to the file fileID, as for PreflightUpload. name may be empty to
+// keep the file's name.
+func (c *client) PreflightUploadVersion(fileID, name string, size int64) error {
+	return c.preflight("/files/"+fileID+"/content", PreflightAttributes{
+		Name: name,
+		Size: size,
+	})
+}
+
+func (c *client) preflight(endpointPath string, attr PreflightAttributes) error {
+	attrJSON, err := json.Marshal(attr)
 	if err != nil {
-		return nil, err
+		return err
 	}
+	_, err = c.sendBody("OPTIONS", endpointPath, "application/json", bytes.NewReader(attrJSON), false)
+	return err
+}
 
-	fileBody := &bytes.Buffer{}
-	writer := multipart.NewWriter(fileBody)
+// uploadMultipart posts a multipart form containing attr, if not nil, and the
+// content of r to the upload endpoint. The form is streamed to the server as
+// it is written rather than being buffered in memory.
+func (c *client) uploadMultipart(endpointPath string, attr []byte, name string, r io.Reader, size int64) ([]byte, error) {
+	boundary := multipart.NewWriter(nil).Boundary()
+	var pr *io.PipeReader
+	var done chan struct{}
+	body := func() io.ReadCloser {
+		if pr != nil {
+			// Stop the previous attempt from reading r before it is
+			// rewound.
+			pr.Close()
+			<-done
+		}
+		var pw *io.PipeWriter
+		pr, pw = io.Pipe()
+		done = make(chan struct{})
+		writer := multipart.NewWriter(pw)
+		writer.SetBoundary(boundary)
+		go func(done chan struct{}) {
+			pw.CloseWithError(writeUploadForm(writer, attr, name, r))
+			close(done)
+		}(done)
+		return pr
+	}
 
-	filePart, err := writer.CreateFormFile("file", filename)
+	req, err := c.newRequest("POST", c.uploadEndpointURL(endpointPath), nil)
 	if err != nil {
 		return nil, err
 	}
+	req.Body = body()
+	req.Header.Set("Content-Type", "multipart/form-data; boundary="+boundary)
+	// New versions are uploaded without attributes.
+	if attr == nil {
+		c.setIfMatch(req)
+	}
+	if size >= 0 {
+		overhead, err := uploadFormOverhead(boundary, attr, name)
+		if err != nil {
+			req.Body.Close()
+			return nil, err
+		}
+		req.ContentLength = overhead + size
+	}
 
-	if _, err := io.Copy(filePart, file); err != nil {
-		return nil, err
+	// Content that can be read again from the start, such as a local file,
+	// allows the request to be retried.
+	if seeker, ok := r.(io.Seeker); ok {
+		if start, err := seeker.Seek(0, io.SeekCurrent); err == nil {
+			req.GetBody = func() (io.ReadCloser, error) {
+				if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+					return nil, err
+				}
+				return body(), nil
+			}
+		}
 	}
 
-	if err := writer.Close(); err != nil {
-		return nil, err
+	return c.do(req)
+}
+
+func writeUploadForm(writer *multipart.Writer, attr []byte, name string, r io.Reader) error {
+	if attr != nil {
+		if err := writer.WriteField("attributes", string(attr)); err != nil {
+			return err
+		}
 	}
 
-	respBody, err := c.Post("/files/"+fileID+"/content", writer.FormDataContentType(), fileBody, true)
+	filePart, err := writer.CreateFormFile("file", name)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return handleUploadResponse(respBody)
+	if _, err := io.Copy(filePart, r); err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
+
+// uploadFormOverhead returns the number of bytes writeUploadForm writes in
+// addition to the file content.
+func uploadFormOverhead(boundary string, attr []byte, name string) (int64, error) {
+	var counter byteCounter
+	writer := multipart.NewWriter(&counter)
+	if err := writer.SetBoundary(boundary); err != nil {
+		return 0, err
+	}
+	if err := writeUploadForm(writer, attr, name, &bytes.Buffer{}); err != nil {
+		return 0, err
+	}
+	return int64(counter), nil
+}
+
+type byteCounter int64
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	*c += byteCounter(len(p))
+	return len(p), nil
 }
 
 func (c *client) DeleteFile(id string) error {
-	_, err := c.Delete("/files/" + id)
-	return err
+	return c.deleteItem("/files/" + id)
+}
+
+// MoveFile moves the file id into the folder parentID, renaming it to name
+// unless name is empty. Unlike deleting and uploading the file again, moving
+// keeps its versions, comments and shared links.
+func (c *client) MoveFile(id, parentID, name string) (*File, error) {
+	var file File
+	if err := c.updateItem("PUT", "/files/"+id, parentID, name, &file); err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+func (c *client) RenameFile(id, name string) (*File, error) {
+	return c.MoveFile(id, "", name)
+}
+
+// CopyFile copies the file id into the folder parentID. The copy keeps the
+// original name if name is empty.
+func (c *client) CopyFile(id, parentID, name string) (*File, error) {
+	var file File
+	if err := c.copyItem("/files/"+id, parentID, name, &file); err != nil {
+		return nil, err
+	}
+	return &file, nil
 }
 
 func attributesJSON(filename, parentID string) ([]byte, error) {
@@ -0,0 +1,111 @@
+package box
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUploadReader(t *testing.T) {
+	var contentLength int64
+	var attributes, content string
+	server, client := newTestHandlerClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/files/content" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		contentLength = r.ContentLength
+		attributes = r.FormValue("attributes")
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		data, _ := ioutil.ReadAll(file)
+		content = string(data)
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintln(w, `{"total_count": 1, "entries": [{"id": "1234", "name": "hello.txt"}]}`)
+	}))
+	defer server.Close()
+
+	file, err := client.UploadReader(strings.NewReader("hello world"), "hello.txt", "0", 11)
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, "1234", file.ID, "ID should be \"1234\"")
+	assert.True(t, contentLength > 11, "Content-Length should be set")
+	assert.Equal(t, `{"name":"hello.txt","parent":{"id":"0"}}`, attributes, "Attributes should be sent")
+	assert.Equal(t, "hello world", content, "Content should be sent")
+
+	file, err = client.UploadReader(strings.NewReader("hello stdin"), "hello.txt", "0", -1)
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, int64(-1), contentLength, "Content-Length should be unknown")
+	assert.Equal(t, "hello stdin", content, "Content should be sent")
+}
+
+func TestMoveAndCopyFile(t *testing.T) {
+	var method, path, body string
+	server, client := newTestHandlerClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		method, path, body = r.Method, r.URL.Path, string(data)
+		fmt.Fprintln(w, `{"type": "file", "id": "5678", "name": "renamed.txt"}`)
+	}))
+	defer server.Close()
+
+	file, err := client.RenameFile("1234", "renamed.txt")
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, "renamed.txt", file.Name, "Name should be \"renamed.txt\"")
+	assert.Equal(t, "PUT", method, "Rename should be a PUT")
+	assert.Equal(t, "/files/1234", path, "Rename should update the file")
+	assert.Equal(t, `{"name":"renamed.txt"}`, body, "Rename should not change the parent")
+
+	_, err = client.MoveFile("1234", "42", "")
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, `{"parent":{"id":"42"}}`, body, "Move should not change the name")
+
+	file, err = client.CopyFile("1234", "42", "")
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, "5678", file.ID, "ID should be the copy's")
+	assert.Equal(t, "POST", method, "Copy should be a POST")
+	assert.Equal(t, "/files/1234/copy", path, "Copy should use the copy endpoint")
+	assert.Equal(t, `{"parent":{"id":"42"}}`, body, "Copy should keep the name")
+}
+
+func TestPreflightUpload(t *testing.T) {
+	var method, path, body string
+	server, client := newTestHandlerClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		method, path, body = r.Method, r.URL.Path, string(data)
+		switch {
+		case strings.Contains(body, `"name":"taken.txt"`):
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprintln(w, `{"type": "error", "status": 409, "code": "item_name_in_use",
+				"context_info": {"conflicts": {"type": "file", "id": "7", "sha1": "abc"}}}`)
+		case strings.Contains(body, `"size":5000000000`):
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprintln(w, `{"type": "error", "status": 403, "code": "storage_limit_exceeded"}`)
+		default:
+			fmt.Fprintln(w, `{"upload_url": "https://upload.box.com/api/2.0/files/content", "upload_token": null}`)
+		}
+	}))
+	defer server.Close()
+
+	err := client.PreflightUpload("new.txt", "42", 100)
+	assert.NoError(t, err, "Allowed upload should pass the preflight check")
+	assert.Equal(t, "OPTIONS", method, "Preflight should be an OPTIONS request")
+	assert.Equal(t, "/files/content", path, "Preflight should be sent to the upload endpoint path")
+	assert.Equal(t, `{"name":"new.txt","parent":{"id":"42"},"size":100}`, body, "Name, parent and size should be sent")
+
+	err = client.PreflightUpload("taken.txt", "42", 100)
+	assert.True(t, IsItemNameInUse(err), "Name conflict should be reported")
+	conflicts := err.(*APIError).Conflicts()
+	assert.Len(t, conflicts, 1, "Conflicting file should be reported")
+	assert.Equal(t, "7", conflicts[0].ID, "Conflicting file should be decoded")
+
+	err = client.PreflightUploadVersion("7", "", 5000000000)
+	assert.True(t, IsQuotaExceeded(err), "Exhausted quota should be reported")
+	assert.Equal(t, "/files/7/content", path, "Version preflight should be sent to the file's content")
+	assert.Equal(t, `{"size":5000000000}`, body, "Only the size should be sent for a version")
+}
@@ -6,7 +6,7 @@ import (
 )
 
 func (c *client) GetFolder(id string) (*Folder, error) {
-	body, err := c.Get("/folders/" + id)
+	body, err := c.getItem("/folders/" + id)
 	if err != nil {
 		return nil, err
 	}
@@ -18,46 +18,27 @@ func (c *client) GetFolder(id string) (*Folder, error) {
 	return &folder, nil
 }
 
+// GetFolderContents returns every item in the folder id, requesting as many
+// pages as needed.
 func (c *client) GetFolderContents(id string) (*FolderContents, error) {
-	body, err := c.Get("/folders/" + id + "/items" +
-		"?fields=sequence_id,sha1,name,description,size," +
-		"path_collection,created_at,modified_at,content_created_at," +
-		"content_modified_at,created_by,modified_by,owned_by,parent," +
-		"item_status,tags,has_collaborations,sync_status")
-	if err != nil {
-		return nil, err
+	contents := &FolderContents{ID: id}
+	it := c.GetFolderItems(id, nil)
+	for it.Next() {
+		page := it.Page()
+		contents.Files = append(contents.Files, page.Files...)
+		contents.Folders = append(contents.Folders, page.Folders...)
+		contents.WebLinks = append(contents.WebLinks, page.WebLinks...)
 	}
-
-	var collection Collection
-	err = json.Unmarshal(body, &collection)
-	if err != nil {
+	if err := it.Err(); err != nil {
 		return nil, err
 	}
+	return contents, nil
+}
 
-	var files []File
-	var folders []Folder
-	for _, entry := range collection.Entries {
-		var entryType struct {
-			Type string `json:"type"`
-		}
-		err := json.Unmarshal(entry, &entryType)
-		if err != nil {
-			return nil, err
-		}
-
-		switch entryType.Type {
-		case TypeFile:
-			var file File
-			json.Unmarshal(entry, &file)
-			files = append(files, file)
-		case TypeFolder:
-			var folder Folder
-			json.Unmarshal(entry, &folder)
-			folders = append(folders, folder)
-		}
-	}
-
-	return &FolderContents{ID: id, Files: files, Folders: folders}, nil
+// GetFolderItems returns an iterator over the pages of items in the folder id.
+// opts may be nil to use the defaults.
+func (c *client) GetFolderItems(id string, opts *ItemsOptions) *ItemIterator {
+	return c.newItemIterator(id, "/folders/"+id+"/items", opts)
 }
 
 func (c *client) CreateFolder(name, parentID string) (*Folder, error) {
@@ -78,11 +59,32 @@ func (c *client) CreateFolder(name, parentID string) (*Folder, error) {
 }
 
 func (c *client) DeleteFolder(id string, recursive bool) error {
-	var err error
 	if recursive {
-		_, err = c.Delete("/folders/" + id + "?recursive=true")
-	} else {
-		_, err = c.Delete("/folders/" + id)
+		return c.deleteItem("/folders/" + id + "?recursive=true")
+	}
+	return c.deleteItem("/folders/" + id)
+}
+
+// MoveFolder moves the folder id into the folder parentID, renaming it to name
+// unless name is empty.
+func (c *client) MoveFolder(id, parentID, name string) (*Folder, error) {
+	var folder Folder
+	if err := c.updateItem("PUT", "/folders/"+id, parentID, name, &folder); err != nil {
+		return nil, err
 	}
-	return err
+	return &folder, nil
+}
+
+func (c *client) RenameFolder(id, name string) (*Folder, error) {
+	return c.MoveFolder(id, "", name)
+}
+
+// CopyFolder copies the folder id and everything in it into the folder
+// parentID. The copy keeps the original name if name is empty.
+func (c *client) CopyFolder(id, parentID, name string) (*Folder, error) {
+	var folder Folder
+	if err := c.copyItem("/folders/"+id, parentID, name, &folder); err != nil {
+		return nil, err
+	}
+	return &folder, nil
 }
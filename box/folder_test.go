@@ -0,0 +1,95 @@
+package box
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestPagingClient serves a folder of count files, returning limit items
+// per page with either markers or offsets.
+func newTestPagingClient(count int, queries *[]string) (*client, func()) {
+	server, client := newTestHandlerClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/folders/0/items" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		*queries = append(*queries, r.URL.RawQuery)
+		query := r.URL.Query()
+		limit, _ := strconv.Atoi(query.Get("limit"))
+		start, _ := strconv.Atoi(query.Get("offset"))
+		if query.Get("usemarker") == "true" {
+			start, _ = strconv.Atoi(query.Get("marker"))
+		}
+
+		entries := ""
+		end := start
+		for ; end < count && end < start+limit; end++ {
+			if entries != "" {
+				entries += ","
+			}
+			entries += fmt.Sprintf(`{"type": "file", "id": "%d"}`, end)
+		}
+
+		if query.Get("usemarker") == "true" {
+			nextMarker := ""
+			if end < count {
+				nextMarker = strconv.Itoa(end)
+			}
+			fmt.Fprintf(w, `{"entries": [%s], "limit": %d, "next_marker": "%s"}`, entries, limit, nextMarker)
+		} else {
+			fmt.Fprintf(w, `{"entries": [%s], "limit": %d, "offset": %d, "total_count": %d}`, entries, limit, start, count)
+		}
+	}))
+	return client, server.Close
+}
+
+func TestGetFolderContentsPaginates(t *testing.T) {
+	var queries []string
+	client, closeServer := newTestPagingClient(2500, &queries)
+	defer closeServer()
+
+	contents, err := client.GetFolderContents("0")
+	assert.NoError(t, err, "Function should not return error")
+	assert.Len(t, contents.Files, 2500, "All files should be listed")
+	assert.Equal(t, "2499", contents.Files[2499].ID, "Files should be in order")
+	assert.Len(t, queries, 3, "3 pages should be requested")
+}
+
+func TestGetFolderItemsOffset(t *testing.T) {
+	var queries []string
+	client, closeServer := newTestPagingClient(25, &queries)
+	defer closeServer()
+
+	it := client.GetFolderItems("0", &ItemsOptions{Fields: []string{"name"}, Limit: 10, UseOffset: true})
+	var pages []int
+	for it.Next() {
+		pages = append(pages, len(it.Page().Files))
+	}
+	assert.NoError(t, it.Err(), "Iteration should not return error")
+	assert.Equal(t, []int{10, 10, 5}, pages, "Items should be returned 10 per page")
+	assert.Equal(t, 25, it.TotalCount(), "Total count should be 25")
+	assert.Equal(t, "fields=name&limit=10&offset=20", queries[2], "Third page should start at offset 20")
+}
+
+func TestMoveFolderConflict(t *testing.T) {
+	server, client := newTestHandlerClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PUT" || r.URL.Path != "/folders/1234" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusConflict)
+		fmt.Fprintln(w, `{"type": "error", "status": 409, "code": "item_name_in_use",
+			"context_info": {"conflicts": [{"type": "folder", "id": "5678", "name": "docs"}]}}`)
+	}))
+	defer server.Close()
+
+	_, err := client.MoveFolder("1234", "42", "docs")
+	assert.True(t, IsItemNameInUse(err), "Error should be item_name_in_use")
+	conflicts := err.(*APIError).Conflicts()
+	assert.Len(t, conflicts, 1, "One conflict should be reported")
+	assert.Equal(t, "5678", conflicts[0].ID, "Conflicting folder should be reported")
+}
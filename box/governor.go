@@ -0,0 +1,177 @@
+package box
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+const (
+	// Defaults for the governor of requests to the API host. Box allows
+	// roughly 1000 API requests per minute per user.
+	DefaultAPIRequestsPerSecond = 12
+	DefaultAPIBurst             = 20
+	DefaultAPIMaxInFlight       = 16
+
+	// Defaults for the governor of requests to the upload host, which Box
+	// limits more strictly.
+	DefaultUploadRequestsPerSecond = 4
+	DefaultUploadBurst             = 4
+	DefaultUploadMaxInFlight       = 4
+)
+
+// Governor limits the rate and the concurrency of the requests sent to one
+// Box host. A Governor may be shared by several clients so that together they
+// stay within the limits.
+type Governor struct {
+	rate  float64 // Requests per second, or unlimited if not positive.
+	burst float64
+	slots chan struct{} // Nil if the number of requests in flight is unlimited.
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+	stats  GovernorStats
+}
+
+// GovernorStats describes the requests that have passed through a Governor.
+type GovernorStats struct {
+	Requests  int64         // The number of requests admitted.
+	Delayed   int64         // The number of requests that had to wait to be admitted.
+	TotalWait time.Duration // The total time requests spent waiting.
+	MaxWait   time.Duration // The longest time a single request waited.
+	InFlight  int           // The number of requests currently in flight.
+}
+
+// NewGovernor returns a Governor that admits requestsPerSecond requests per
+// second on average with bursts of up to burst requests, and at most
+// maxInFlight requests at the same time. A limit that is not positive is not
+// enforced.
+func NewGovernor(requestsPerSecond float64, burst, maxInFlight int) *Governor {
+	if burst < 1 {
+		burst = 1
+	}
+	g := &Governor{
+		rate:   requestsPerSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+	if maxInFlight > 0 {
+		g.slots = make(chan struct{}, maxInFlight)
+	}
+	return g
+}
+
+// Acquire waits until a request may be sent, or ctx is done. The returned
+// function must be called once the request has completed.
+func (g *Governor) Acquire(ctx context.Context) (func(), error) {
+	start := time.Now()
+
+	if delay := g.reserve(); delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			g.unreserve()
+			return nil, ctx.Err()
+		}
+	}
+
+	if g.slots != nil {
+		select {
+		case g.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	g.admit(time.Since(start))
+
+	var once sync.Once
+	return func() {
+		once.Do(g.release)
+	}, nil
+}
+
+// Stats returns a snapshot of the Governor's statistics.
+func (g *Governor) Stats() GovernorStats {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.stats
+}
+
+// reserve takes a token from the bucket and returns how long to wait until
+// the token is actually available.
+func (g *Governor) reserve() time.Duration {
+	if g.rate <= 0 {
+		return 0
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	g.tokens += now.Sub(g.last).Seconds() * g.rate
+	if g.tokens > g.burst {
+		g.tokens = g.burst
+	}
+	g.last = now
+
+	g.tokens--
+	if g.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-g.tokens / g.rate * float64(time.Second))
+}
+
+// unreserve returns a token taken by reserve for a request that was
+// abandoned.
+func (g *Governor) unreserve() {
+	if g.rate <= 0 {
+		return
+	}
+	g.mu.Lock()
+	g.tokens++
+	g.mu.Unlock()
+}
+
+func (g *Governor) admit(wait time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.stats.Requests++
+	g.stats.InFlight++
+	// Waits shorter than this are just the cost of acquiring the governor.
+	if wait > time.Millisecond {
+		g.stats.Delayed++
+		g.stats.TotalWait += wait
+		if wait > g.stats.MaxWait {
+			g.stats.MaxWait = wait
+		}
+	}
+}
+
+func (g *Governor) release() {
+	g.mu.Lock()
+	g.stats.InFlight--
+	g.mu.Unlock()
+	if g.slots != nil {
+		<-g.slots
+	}
+}
+
+// releaseOnClose calls release when the response body it wraps is closed, so
+// that a request counts as in flight until its response has been read.
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+}
+
+func (r *releaseOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.release()
+	return err
+}
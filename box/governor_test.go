@@ -0,0 +1,83 @@
+package box
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+func TestGovernorRateLimit(t *testing.T) {
+	governor := NewGovernor(100, 2, 0)
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		release, err := governor.Acquire(context.Background())
+		assert.NoError(t, err, "Function should not return error")
+		release()
+	}
+	elapsed := time.Since(start)
+
+	// The burst of 2 is admitted immediately, the other 2 requests wait 10ms
+	// each.
+	assert.True(t, elapsed >= 15*time.Millisecond, "Requests beyond the burst should wait, took %v", elapsed)
+	stats := governor.Stats()
+	assert.Equal(t, int64(4), stats.Requests, "4 requests should be admitted")
+	assert.Equal(t, int64(2), stats.Delayed, "2 requests should be delayed")
+	assert.Equal(t, 0, stats.InFlight, "No requests should be in flight")
+}
+
+func TestGovernorMaxInFlight(t *testing.T) {
+	governor := NewGovernor(0, 0, 1)
+
+	release, err := governor.Acquire(context.Background())
+	assert.NoError(t, err, "Function should not return error")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = governor.Acquire(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err, "Second request should wait for the first")
+
+	release()
+	release, err = governor.Acquire(context.Background())
+	assert.NoError(t, err, "Request should be admitted once the first is released")
+	release()
+}
+
+func TestClientRequestsPassThroughGovernor(t *testing.T) {
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	server, client := newTestHandlerClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		w.Write([]byte(`{"id": "1234"}`))
+	}))
+	defer server.Close()
+	client.apiGovernor = NewGovernor(0, 0, 2)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client.GetCurrentUser()
+		}()
+	}
+	wg.Wait()
+
+	api, _ := client.RequestStats()
+	assert.Equal(t, int64(8), api.Requests, "8 requests should be admitted")
+	assert.Equal(t, 0, api.InFlight, "No requests should be in flight")
+	assert.True(t, maxInFlight <= 2, "At most 2 requests should be in flight, saw %d", maxInFlight)
+}
@@ -0,0 +1,104 @@
+package box
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+)
+
+const (
+	GroupRoleMember = "member"
+	GroupRoleAdmin  = "admin"
+)
+
+// GetGroups returns the groups of the current user's enterprise.
+func (c *client) GetGroups() ([]Group, error) {
+	var groups []Group
+	for {
+		body, err := c.Get("/groups?limit=100&offset=" + strconv.Itoa(len(groups)))
+		if err != nil {
+			return nil, err
+		}
+		var collection GroupCollection
+		err = json.Unmarshal(body, &collection)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, collection.Entries...)
+		if len(collection.Entries) == 0 || len(groups) >= collection.Count {
+			return groups, nil
+		}
+	}
+}
+
+func (c *client) CreateGroup(name, description string) (*Group, error) {
+	attrJSON, err := json.Marshal(GroupAttributes{Name: name, Description: description})
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.Post("/groups", "application/json", bytes.NewReader(attrJSON), false)
+	if err != nil {
+		return nil, err
+	}
+	var group Group
+	err = json.Unmarshal(body, &group)
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+// DeleteGroup deletes the group id, which also removes its memberships and
+// collaborations.
+func (c *client) DeleteGroup(id string) error {
+	_, err := c.Delete("/groups/" + id)
+	return err
+}
+
+// GetGroupMembers returns the memberships of the users in the group groupID.
+func (c *client) GetGroupMembers(groupID string) ([]GroupMembership, error) {
+	var memberships []GroupMembership
+	for {
+		body, err := c.Get("/groups/" + groupID + "/memberships?limit=100&offset=" + strconv.Itoa(len(memberships)))
+		if err != nil {
+			return nil, err
+		}
+		var collection GroupMembershipCollection
+		err = json.Unmarshal(body, &collection)
+		if err != nil {
+			return nil, err
+		}
+		memberships = append(memberships, collection.Entries...)
+		if len(collection.Entries) == 0 || len(memberships) >= collection.Count {
+			return memberships, nil
+		}
+	}
+}
+
+// AddGroupMember adds the user userID to the group groupID with role, or as a
+// member if role is empty.
+func (c *client) AddGroupMember(groupID, userID, role string) (*GroupMembership, error) {
+	attrJSON, err := json.Marshal(GroupMembershipAttributes{
+		User:  ItemReference{Type: TypeUser, ID: userID},
+		Group: ItemReference{Type: TypeGroup, ID: groupID},
+		Role:  role,
+	})
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.Post("/group_memberships", "application/json", bytes.NewReader(attrJSON), false)
+	if err != nil {
+		return nil, err
+	}
+	var membership GroupMembership
+	err = json.Unmarshal(body, &membership)
+	if err != nil {
+		return nil, err
+	}
+	return &membership, nil
+}
+
+func (c *client) RemoveGroupMember(membershipID string) error {
+	_, err := c.Delete("/group_memberships/" + membershipID)
+	return err
+}
@@ -0,0 +1,47 @@
+package box
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetGroupMembers(t *testing.T) {
+	server, client := newTestServerClient("/groups/5/memberships?limit=100&offset=0", `{
+		"total_count": 2,
+		"entries": [
+			{"type": "group_membership", "id": "11", "role": "admin", "user": {"type": "user", "id": "1", "login": "pi@illinois.edu"}},
+			{"type": "group_membership", "id": "12", "role": "member", "user": {"type": "user", "id": "2", "login": "lab1@illinois.edu"}}
+		]
+	}`)
+	defer server.Close()
+
+	memberships, err := client.GetGroupMembers("5")
+	assert.NoError(t, err, "Function should not return error")
+	assert.Len(t, memberships, 2, "Both members should be returned")
+	assert.Equal(t, GroupRoleAdmin, memberships[0].Role, "Role should be decoded")
+	assert.Equal(t, "lab1@illinois.edu", memberships[1].User.Login, "Member should be decoded")
+}
+
+func TestAddGroupMember(t *testing.T) {
+	var body string
+	server, client := newTestHandlerClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/group_memberships" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		data, _ := ioutil.ReadAll(r.Body)
+		body = string(data)
+		fmt.Fprintln(w, `{"type": "group_membership", "id": "13", "role": "member", "group": {"type": "group", "id": "5"}}`)
+	}))
+	defer server.Close()
+
+	membership, err := client.AddGroupMember("5", "3", "")
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, "13", membership.ID, "Membership should be decoded")
+	assert.Equal(t, "5", membership.Group.ID, "Group should be decoded")
+	assert.Equal(t, `{"user":{"type":"user","id":"3"},"group":{"type":"group","id":"5"}}`, body, "User and group should be sent")
+}
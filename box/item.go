@@ -0,0 +1,59 @@
+package box
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// updateItem sends a request with method to the file or folder at
+// endpointPath that sets its parent and name, and decodes the updated item
+// into v. An empty parentID or name leaves that attribute unchanged. Moves are
+// PUT to the item and restores from the trash are POSTed to it.
+func (c *client) updateItem(method, endpointPath, parentID, name string, v interface{}) error {
+	update := ItemUpdate{Name: name}
+	if parentID != "" {
+		update.Parent = &Parent{ID: parentID}
+	}
+	updateJSON, err := json.Marshal(update)
+	if err != nil {
+		return err
+	}
+	req, err := c.newRequest(method, c.endpointURL(endpointPath), bytes.NewReader(updateJSON))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	c.setIfMatch(req)
+	body, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+// deleteItem deletes the file or folder at endpointPath, which may carry a
+// query string.
+func (c *client) deleteItem(endpointPath string) error {
+	req, err := c.newRequest("DELETE", c.endpointURL(endpointPath), nil)
+	if err != nil {
+		return err
+	}
+	c.setIfMatch(req)
+	_, err = c.do(req)
+	return err
+}
+
+// copyItem copies the file or folder at endpointPath into the folder parentID
+// and decodes the new item into v. The copy keeps the original name if name
+// is empty.
+func (c *client) copyItem(endpointPath, parentID, name string, v interface{}) error {
+	copyJSON, err := json.Marshal(ItemCopy{Name: name, Parent: Parent{ID: parentID}})
+	if err != nil {
+		return err
+	}
+	body, err := c.Post(endpointPath+"/copy", "application/json", bytes.NewReader(copyJSON), false)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
@@ -0,0 +1,179 @@
+package box
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultPageSize is the number of items requested per page when
+	// ItemsOptions.Limit is not set. It is the largest page Box allows.
+	DefaultPageSize = 1000
+)
+
+// DefaultItemFields are the fields requested for each item when
+// ItemsOptions.Fields is not set.
+var DefaultItemFields = []string{
+	"sequence_id", "etag", "sha1", "name", "description", "size",
+	"path_collection", "created_at", "modified_at", "content_created_at",
+	"content_modified_at", "created_by", "modified_by", "owned_by", "parent",
+	"item_status", "tags", "has_collaborations", "sync_status", "lock", "url",
+}
+
+// ItemsOptions controls how a listing of items is requested.
+type ItemsOptions struct {
+	Fields    []string // The fields to return for each item.
+	Limit     int      // The number of items per page.
+	UseOffset bool     // Page with offsets instead of markers, e.g. to get the total count.
+}
+
+// ItemIterator iterates over a listing of items one page at a time:
+//
+//	it := client.GetFolderItems(id, nil)
+//	for it.Next() {
+//		page := it.Page()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type ItemIterator struct {
+	client       *client
+	id           string
+	endpointPath string
+	params       url.Values // Parameters sent with every page request besides the paging ones.
+	opts         ItemsOptions
+
+	page       *FolderContents
+	marker     string
+	offset     int
+	totalCount int
+	done       bool
+	err        error
+}
+
+func (c *client) newItemIterator(id, endpointPath string, opts *ItemsOptions) *ItemIterator {
+	it := &ItemIterator{
+		client:       c,
+		id:           id,
+		endpointPath: endpointPath,
+	}
+	if opts != nil {
+		it.opts = *opts
+	}
+	if len(it.opts.Fields) == 0 {
+		it.opts.Fields = DefaultItemFields
+	}
+	if it.opts.Limit <= 0 {
+		it.opts.Limit = DefaultPageSize
+	}
+	return it
+}
+
+// Next requests the next page of items, which is then available from Page.
+// It returns false when there are no more items or an error occurred.
+func (it *ItemIterator) Next() bool {
+	if it.done || it.err != nil {
+		return false
+	}
+
+	query := url.Values{}
+	for key, values := range it.params {
+		query[key] = values
+	}
+	query.Set("fields", strings.Join(it.opts.Fields, ","))
+	query.Set("limit", strconv.Itoa(it.opts.Limit))
+	if it.opts.UseOffset {
+		query.Set("offset", strconv.Itoa(it.offset))
+	} else {
+		query.Set("usemarker", "true")
+		if it.marker != "" {
+			query.Set("marker", it.marker)
+		}
+	}
+
+	body, err := it.client.Get(it.endpointPath + "?" + query.Encode())
+	if err != nil {
+		it.err = err
+		return false
+	}
+
+	var collection Collection
+	err = json.Unmarshal(body, &collection)
+	if err != nil {
+		it.err = err
+		return false
+	}
+
+	page := &FolderContents{ID: it.id}
+	err = parseItems(collection.Entries, page)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	it.page = page
+	it.totalCount = collection.Count
+
+	if it.opts.UseOffset {
+		it.offset += len(collection.Entries)
+		it.done = len(collection.Entries) == 0 || it.offset >= collection.Count
+	} else {
+		it.marker = collection.NextMarker
+		it.done = collection.NextMarker == ""
+	}
+	return true
+}
+
+// Page returns the page of items requested by the last call to Next.
+func (it *ItemIterator) Page() *FolderContents {
+	return it.page
+}
+
+// TotalCount returns the total number of items in the listing. It is only
+// known after the first page has been requested, and only with offset paging.
+func (it *ItemIterator) TotalCount() int {
+	return it.totalCount
+}
+
+// Err returns the error, if any, that stopped the iteration.
+func (it *ItemIterator) Err() error {
+	return it.err
+}
+
+// parseItems decodes entries, which may be files, folders or web links, into
+// contents.
+func parseItems(entries []json.RawMessage, contents *FolderContents) error {
+	for _, entry := range entries {
+		var entryType struct {
+			Type string `json:"type"`
+		}
+		err := json.Unmarshal(entry, &entryType)
+		if err != nil {
+			return err
+		}
+
+		switch entryType.Type {
+		case TypeFile:
+			var file File
+			if err := json.Unmarshal(entry, &file); err != nil {
+				return err
+			}
+			contents.Files = append(contents.Files, file)
+		case TypeFolder:
+			var folder Folder
+			if err := json.Unmarshal(entry, &folder); err != nil {
+				return err
+			}
+			contents.Folders = append(contents.Folders, folder)
+		case TypeWebLink:
+			var webLink WebLink
+			if err := json.Unmarshal(entry, &webLink); err != nil {
+				return err
+			}
+			contents.WebLinks = append(contents.WebLinks, webLink)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package box
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// LockFile locks the file id so that other users cannot edit, move or delete
+// it until it is unlocked or the lock expires at expiresAt, which may be nil
+// for a lock that does not expire. If preventDownload is true other users
+// cannot download the file either.
+func (c *client) LockFile(id string, expiresAt *time.Time, preventDownload bool) (*Lock, error) {
+	lock, err := c.setLock(id, &LockSettings{
+		Access:              TypeLock,
+		ExpiresAt:           expiresAt,
+		IsDownloadPrevented: preventDownload,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if lock == nil {
+		return nil, errors.New("file was not locked")
+	}
+	return lock, nil
+}
+
+func (c *client) UnlockFile(id string) error {
+	_, err := c.setLock(id, nil)
+	return err
+}
+
+// Active reports whether l is still in effect at t. A nil lock is never
+// active.
+func (l *Lock) Active(t time.Time) bool {
+	return l != nil && (l.ExpiredAt == nil || l.ExpiredAt.After(t))
+}
+
+// setLock replaces the lock on the file id with settings, removing it if
+// settings is nil, and returns the resulting lock.
+func (c *client) setLock(id string, settings *LockSettings) (*Lock, error) {
+	var update struct {
+		Lock *LockSettings `json:"lock"`
+	}
+	update.Lock = settings
+	updateJSON, err := json.Marshal(update)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.Put("/files/"+id+"?fields=lock", "application/json", bytes.NewReader(updateJSON), false)
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Lock *Lock `json:"lock"`
+	}
+	err = json.Unmarshal(body, &file)
+	if err != nil {
+		return nil, err
+	}
+	return file.Lock, nil
+}
@@ -0,0 +1,47 @@
+package box
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockFile(t *testing.T) {
+	var query, body string
+	server, client := newTestHandlerClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		query, body = r.URL.RawQuery, string(data)
+		if r.Method != "PUT" || r.URL.Path != "/files/42" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if body == `{"lock":null}` {
+			fmt.Fprintln(w, `{"type": "file", "id": "42", "lock": null}`)
+			return
+		}
+		fmt.Fprintln(w, `{"type": "file", "id": "42", "lock": {"type": "lock", "id": "7",
+			"created_by": {"type": "user", "id": "9"}, "expired_at": "2026-11-01T17:00:00Z", "is_download_prevented": true}}`)
+	}))
+	defer server.Close()
+
+	expiresAt := time.Date(2026, 11, 1, 17, 0, 0, 0, time.UTC)
+	lock, err := client.LockFile("42", &expiresAt, true)
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, "fields=lock", query, "Lock should be requested in the response")
+	assert.Equal(t, `{"lock":{"access":"lock","expires_at":"2026-11-01T17:00:00Z","is_download_prevented":true}}`, body, "Lock settings should be sent")
+	assert.Equal(t, "9", lock.CreatedBy.ID, "Lock owner should be decoded")
+	assert.True(t, lock.IsDownloadPrevented, "Download prevention should be decoded")
+	assert.True(t, lock.Active(expiresAt.Add(-time.Minute)), "Lock should be active before it expires")
+	assert.False(t, lock.Active(expiresAt), "Lock should not be active once it expires")
+
+	err = client.UnlockFile("42")
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, `{"lock":null}`, body, "Unlocking should remove the lock")
+
+	var noLock *Lock
+	assert.False(t, noLock.Active(expiresAt), "Missing lock should not be active")
+}
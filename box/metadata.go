@@ -0,0 +1,246 @@
+package box
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+const (
+	// MaxMetadataQueryPageSize is the largest page of results Box returns for
+	// a metadata query.
+	MaxMetadataQueryPageSize = 100
+
+	MetadataScopeGlobal     = "global"
+	MetadataScopeEnterprise = "enterprise"
+
+	// MetadataTemplateProperties is the global template that holds free-form
+	// key/value pairs.
+	MetadataTemplateProperties = "properties"
+
+	MetadataFieldString      = "string"
+	MetadataFieldFloat       = "float"
+	MetadataFieldDate        = "date"
+	MetadataFieldEnum        = "enum"
+	MetadataFieldMultiSelect = "multiSelect"
+
+	MetadataOpAdd     = "add"
+	MetadataOpReplace = "replace"
+	MetadataOpRemove  = "remove"
+	MetadataOpTest    = "test"
+	MetadataOpMove    = "move"
+	MetadataOpCopy    = "copy"
+)
+
+// Scope returns the scope of the template m is an instance of.
+func (m Metadata) Scope() string {
+	scope, _ := m["$scope"].(string)
+	return scope
+}
+
+// Template returns the key of the template m is an instance of.
+func (m Metadata) Template() string {
+	template, _ := m["$template"].(string)
+	return template
+}
+
+// Keys returns the sorted keys of the template fields set in m, leaving out
+// those set by Box.
+func (m Metadata) Keys() []string {
+	var keys []string
+	for key := range m {
+		if !strings.HasPrefix(key, "$") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// GetMetadataTemplates returns the templates available in scope, either
+// MetadataScopeEnterprise or MetadataScopeGlobal.
+func (c *client) GetMetadataTemplates(scope string) ([]MetadataTemplate, error) {
+	var templates []MetadataTemplate
+	marker := ""
+	for {
+		query := url.Values{}
+		query.Set("limit", "100")
+		if marker != "" {
+			query.Set("marker", marker)
+		}
+		body, err := c.Get("/metadata_templates/" + scope + "?" + query.Encode())
+		if err != nil {
+			return nil, err
+		}
+		var collection MetadataTemplateCollection
+		err = json.Unmarshal(body, &collection)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, collection.Entries...)
+		if collection.NextMarker == "" {
+			return templates, nil
+		}
+		marker = collection.NextMarker
+	}
+}
+
+func (c *client) GetMetadataTemplate(scope, templateKey string) (*MetadataTemplate, error) {
+	body, err := c.Get("/metadata_templates/" + scope + "/" + templateKey + "/schema")
+	if err != nil {
+		return nil, err
+	}
+	var template MetadataTemplate
+	err = json.Unmarshal(body, &template)
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+func (c *client) GetFileMetadataList(fileID string) ([]Metadata, error) {
+	return c.getMetadataList("/files/" + fileID)
+}
+
+// GetFileMetadata returns the instance of the template templateKey on the
+// file fileID. The error satisfies IsNotFound if the file has none.
+func (c *client) GetFileMetadata(fileID, scope, templateKey string) (Metadata, error) {
+	return c.getMetadata(metadataPath("/files/"+fileID, scope, templateKey))
+}
+
+// CreateFileMetadata applies the template templateKey to the file fileID with
+// values for its fields. The error satisfies IsConflict if the file already
+// has an instance of the template, which must be changed with
+// UpdateFileMetadata instead.
+func (c *client) CreateFileMetadata(fileID, scope, templateKey string, values map[string]interface{}) (Metadata, error) {
+	return c.createMetadata(metadataPath("/files/"+fileID, scope, templateKey), values)
+}
+
+// UpdateFileMetadata applies ops to the instance of the template templateKey
+// on the file fileID. Either all operations succeed or none are applied.
+func (c *client) UpdateFileMetadata(fileID, scope, templateKey string, ops []MetadataOperation) (Metadata, error) {
+	return c.updateMetadata(metadataPath("/files/"+fileID, scope, templateKey), ops)
+}
+
+func (c *client) DeleteFileMetadata(fileID, scope, templateKey string) error {
+	_, err := c.Delete(metadataPath("/files/"+fileID, scope, templateKey))
+	return err
+}
+
+func (c *client) GetFolderMetadataList(folderID string) ([]Metadata, error) {
+	return c.getMetadataList("/folders/" + folderID)
+}
+
+func (c *client) GetFolderMetadata(folderID, scope, templateKey string) (Metadata, error) {
+	return c.getMetadata(metadataPath("/folders/"+folderID, scope, templateKey))
+}
+
+func (c *client) CreateFolderMetadata(folderID, scope, templateKey string, values map[string]interface{}) (Metadata, error) {
+	return c.createMetadata(metadataPath("/folders/"+folderID, scope, templateKey), values)
+}
+
+func (c *client) UpdateFolderMetadata(folderID, scope, templateKey string, ops []MetadataOperation) (Metadata, error) {
+	return c.updateMetadata(metadataPath("/folders/"+folderID, scope, templateKey), ops)
+}
+
+func (c *client) DeleteFolderMetadata(folderID, scope, templateKey string) error {
+	_, err := c.Delete(metadataPath("/folders/"+folderID, scope, templateKey))
+	return err
+}
+
+// QueryMetadata returns all files and folders matching query. Metadata is
+// only returned for the items if it is requested in query.Fields.
+func (c *client) QueryMetadata(query MetadataQuery) (*FolderContents, error) {
+	if query.Limit <= 0 || query.Limit > MaxMetadataQueryPageSize {
+		query.Limit = MaxMetadataQueryPageSize
+	}
+	query.Marker = ""
+
+	contents := &FolderContents{ID: query.AncestorFolderID}
+	for {
+		queryJSON, err := json.Marshal(query)
+		if err != nil {
+			return nil, err
+		}
+		body, err := c.Post("/metadata_queries/execute_read", "application/json", bytes.NewReader(queryJSON), false)
+		if err != nil {
+			return nil, err
+		}
+		var collection Collection
+		err = json.Unmarshal(body, &collection)
+		if err != nil {
+			return nil, err
+		}
+		err = parseItems(collection.Entries, contents)
+		if err != nil {
+			return nil, err
+		}
+		if collection.NextMarker == "" {
+			return contents, nil
+		}
+		query.Marker = collection.NextMarker
+	}
+}
+
+func metadataPath(itemPath, scope, templateKey string) string {
+	return itemPath + "/metadata/" + scope + "/" + templateKey
+}
+
+func (c *client) getMetadataList(itemPath string) ([]Metadata, error) {
+	body, err := c.Get(itemPath + "/metadata")
+	if err != nil {
+		return nil, err
+	}
+	var collection MetadataCollection
+	err = json.Unmarshal(body, &collection)
+	if err != nil {
+		return nil, err
+	}
+	return collection.Entries, nil
+}
+
+func (c *client) getMetadata(endpointPath string) (Metadata, error) {
+	body, err := c.Get(endpointPath)
+	if err != nil {
+		return nil, err
+	}
+	return decodeMetadata(body)
+}
+
+func (c *client) createMetadata(endpointPath string, values map[string]interface{}) (Metadata, error) {
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+	valuesJSON, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.Post(endpointPath, "application/json", bytes.NewReader(valuesJSON), false)
+	if err != nil {
+		return nil, err
+	}
+	return decodeMetadata(body)
+}
+
+func (c *client) updateMetadata(endpointPath string, ops []MetadataOperation) (Metadata, error) {
+	opsJSON, err := json.Marshal(ops)
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.Put(endpointPath, "application/json-patch+json", bytes.NewReader(opsJSON), false)
+	if err != nil {
+		return nil, err
+	}
+	return decodeMetadata(body)
+}
+
+func decodeMetadata(body []byte) (Metadata, error) {
+	var metadata Metadata
+	err := json.Unmarshal(body, &metadata)
+	if err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
@@ -0,0 +1,114 @@
+package box
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetadata(t *testing.T) {
+	var method, path, contentType, body string
+	server, client := newTestHandlerClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		method, path, contentType, body = r.Method, r.URL.Path, r.Header.Get("Content-Type"), string(data)
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/metadata_templates/enterprise":
+			if r.URL.Query().Get("marker") == "" {
+				fmt.Fprintln(w, `{"entries": [{"type": "metadata_template", "templateKey": "datasets", "scope": "enterprise_1"}], "next_marker": "m"}`)
+			} else {
+				fmt.Fprintln(w, `{"entries": [{"type": "metadata_template", "templateKey": "contracts", "scope": "enterprise_1"}]}`)
+			}
+		case r.Method == "GET" && r.URL.Path == "/files/42/metadata":
+			fmt.Fprintln(w, `{"entries": [{"$scope": "enterprise_1", "$template": "datasets", "instrument": "NMR", "run": 3}]}`)
+		case r.URL.Path == "/files/42/metadata/enterprise/datasets":
+			switch r.Method {
+			case "POST":
+				w.WriteHeader(http.StatusCreated)
+				fmt.Fprintln(w, `{"$scope": "enterprise_1", "$template": "datasets", "instrument": "NMR"}`)
+			case "PUT":
+				fmt.Fprintln(w, `{"$scope": "enterprise_1", "$template": "datasets", "instrument": "MRI"}`)
+			case "DELETE":
+				w.WriteHeader(http.StatusNoContent)
+			}
+		case r.Method == "GET" && r.URL.Path == "/folders/7/metadata/global/properties":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintln(w, `{"type": "error", "status": 404, "code": "instance_not_found"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	templates, err := client.GetMetadataTemplates(MetadataScopeEnterprise)
+	assert.NoError(t, err, "Function should not return error")
+	assert.Len(t, templates, 2, "Templates from every page should be listed")
+
+	instances, err := client.GetFileMetadataList("42")
+	assert.NoError(t, err, "Function should not return error")
+	assert.Len(t, instances, 1, "One instance should be listed")
+	assert.Equal(t, "enterprise_1", instances[0].Scope(), "Scope should be decoded")
+	assert.Equal(t, "datasets", instances[0].Template(), "Template should be decoded")
+	assert.Equal(t, []string{"instrument", "run"}, instances[0].Keys(), "Only template fields should be listed")
+
+	metadata, err := client.CreateFileMetadata("42", MetadataScopeEnterprise, "datasets", map[string]interface{}{"instrument": "NMR"})
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, "NMR", metadata["instrument"], "New instance should be returned")
+	assert.Equal(t, `{"instrument":"NMR"}`, body, "Values should be sent as an object")
+
+	metadata, err = client.UpdateFileMetadata("42", MetadataScopeEnterprise, "datasets", []MetadataOperation{
+		{Op: MetadataOpReplace, Path: "/instrument", Value: "MRI"},
+		{Op: MetadataOpAdd, Path: "/run", Value: 0},
+		{Op: MetadataOpRemove, Path: "/pi"},
+	})
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, "MRI", metadata["instrument"], "Updated instance should be returned")
+	assert.Equal(t, "application/json-patch+json", contentType, "Update should be a JSON Patch")
+	assert.Equal(t, `[{"op":"replace","path":"/instrument","value":"MRI"},{"op":"add","path":"/run","value":0},{"op":"remove","path":"/pi"}]`,
+		body, "Operations should be sent in order")
+
+	err = client.DeleteFileMetadata("42", MetadataScopeEnterprise, "datasets")
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, "DELETE", method, "Removing should be a DELETE")
+	assert.Equal(t, "/files/42/metadata/enterprise/datasets", path, "Removing should delete the instance")
+
+	_, err = client.GetFolderMetadata("7", MetadataScopeGlobal, MetadataTemplateProperties)
+	assert.True(t, IsNotFound(err), "Missing instance should be reported as not found")
+}
+
+func TestQueryMetadata(t *testing.T) {
+	var queries []string
+	server, client := newTestHandlerClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		if r.Method != "POST" || r.URL.Path != "/metadata_queries/execute_read" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		queries = append(queries, string(data))
+		if len(queries) == 1 {
+			fmt.Fprintln(w, `{"entries": [{"type": "file", "id": "1", "name": "run1.csv",
+				"metadata": {"enterprise_1": {"datasets": {"instrument": "NMR"}}}}], "next_marker": "m"}`)
+		} else {
+			fmt.Fprintln(w, `{"entries": [{"type": "folder", "id": "2", "name": "runs"}]}`)
+		}
+	}))
+	defer server.Close()
+
+	contents, err := client.QueryMetadata(MetadataQuery{
+		From:             "enterprise_1.datasets",
+		Query:            "instrument = :instrument",
+		QueryParams:      map[string]interface{}{"instrument": "NMR"},
+		AncestorFolderID: "0",
+		Fields:           []string{"name", "metadata.enterprise_1.datasets.instrument"},
+	})
+	assert.NoError(t, err, "Function should not return error")
+	assert.Len(t, contents.Files, 1, "Files should be returned")
+	assert.Len(t, contents.Folders, 1, "Folders from the next page should be returned")
+	assert.Equal(t, "NMR", contents.Files[0].Metadata["enterprise_1"]["datasets"]["instrument"], "Requested metadata should be decoded")
+	assert.Len(t, queries, 2, "Every page should be requested")
+	assert.Equal(t, `{"from":"enterprise_1.datasets","query":"instrument = :instrument","query_params":{"instrument":"NMR"},`+
+		`"ancestor_folder_id":"0","fields":["name","metadata.enterprise_1.datasets.instrument"],"limit":100,"marker":"m"}`,
+		queries[1], "Next page should be requested with the marker")
+}
@@ -0,0 +1,189 @@
+package box
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ThumbnailPNG = "png"
+	ThumbnailJPG = "jpg"
+
+	RepresentationPDF           = "pdf"
+	RepresentationExtractedText = "extracted_text"
+	RepresentationJPG           = "jpg"
+	RepresentationPNG           = "png"
+
+	RepresentationStateSuccess  = "success"
+	RepresentationStateViewable = "viewable"
+	RepresentationStatePending  = "pending"
+	RepresentationStateNone     = "none"
+	RepresentationStateError    = "error"
+
+	maxRepresentationPolls     = 30
+	representationPollInterval = 2 * time.Second
+)
+
+// GetThumbnail writes a thumbnail of the file fileID to w, as a PNG or JPEG
+// image depending on extension. opts may be nil to get the default size.
+// Thumbnails that are still being generated are waited for.
+func (c *client) GetThumbnail(fileID, extension string, opts *ThumbnailOptions, w io.Writer) error {
+	query := url.Values{}
+	if opts != nil {
+		for key, value := range map[string]int{
+			"min_width":  opts.MinWidth,
+			"min_height": opts.MinHeight,
+			"max_width":  opts.MaxWidth,
+			"max_height": opts.MaxHeight,
+		} {
+			if value > 0 {
+				query.Set(key, strconv.Itoa(value))
+			}
+		}
+	}
+	thumbnailURL := c.endpointURL("/files/" + fileID + "/thumbnail." + extension)
+	if len(query) > 0 {
+		thumbnailURL += "?" + query.Encode()
+	}
+
+	for attempt := 1; ; attempt++ {
+		req, err := c.newRequest("GET", thumbnailURL, nil)
+		if err != nil {
+			return err
+		}
+		r, err := c.send(req)
+		if err != nil {
+			return err
+		}
+
+		switch r.StatusCode {
+		case http.StatusOK:
+			_, err = io.Copy(w, r.Body)
+			r.Body.Close()
+			return err
+		case http.StatusAccepted:
+			r.Body.Close()
+			if attempt >= maxDownloadAttempts {
+				return errDownloadNotReady
+			}
+			delay := defaultDownloadDelay
+			if seconds, err := strconv.Atoi(r.Header.Get("Retry-After")); err == nil {
+				delay = time.Duration(seconds) * time.Second
+			}
+			if err := c.sleep(delay); err != nil {
+				return err
+			}
+		default:
+			_, err := handleResponse(r)
+			r.Body.Close()
+			if err == nil {
+				err = errors.New("unexpected thumbnail response: " + r.Status)
+			}
+			return err
+		}
+	}
+}
+
+// GetRepresentations returns the representations of the file fileID matching
+// hints, each a representation format optionally followed by its properties,
+// e.g. "pdf" or "jpg?dimensions=1024x1024". Representations that have not
+// been generated yet are returned with a pending or none state and can be
+// waited for with WaitForRepresentation.
+func (c *client) GetRepresentations(fileID string, hints ...string) ([]Representation, error) {
+	req, err := c.newRequest("GET", c.endpointURL("/files/"+fileID+"?fields=representations"), nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(hints) > 0 {
+		req.Header.Set("X-Rep-Hints", "["+strings.Join(hints, "][")+"]")
+	}
+	body, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var file struct {
+		Representations struct {
+			Entries []Representation `json:"entries"`
+		} `json:"representations"`
+	}
+	err = json.Unmarshal(body, &file)
+	if err != nil {
+		return nil, err
+	}
+	return file.Representations.Entries, nil
+}
+
+// WaitForRepresentation asks Box to generate rep if it has not been yet, and
+// polls its status until its content can be downloaded. It returns the
+// representation with its final status.
+func (c *client) WaitForRepresentation(rep *Representation) (*Representation, error) {
+	for attempt := 0; ; attempt++ {
+		switch rep.Status.State {
+		case RepresentationStateSuccess, RepresentationStateViewable:
+			return rep, nil
+		case RepresentationStatePending, RepresentationStateNone:
+		default:
+			return nil, fmt.Errorf("%s representation could not be generated", rep.Representation)
+		}
+		if attempt >= maxRepresentationPolls {
+			return nil, fmt.Errorf("%s representation is still %s", rep.Representation, rep.Status.State)
+		}
+		// The first request to the info URL starts the generation.
+		if attempt > 0 {
+			if err := c.sleep(representationPollInterval); err != nil {
+				return nil, err
+			}
+		}
+
+		body, err := c.GetByURL(rep.Info.URL)
+		if err != nil {
+			return nil, err
+		}
+		var polled Representation
+		err = json.Unmarshal(body, &polled)
+		if err != nil {
+			return nil, err
+		}
+		rep = &polled
+	}
+}
+
+// DownloadRepresentation writes the asset assetPath of rep to w. assetPath is
+// empty for representations made of a single asset, such as PDFs and
+// extracted text, and names a page, e.g. "1.png", for paged ones.
+func (c *client) DownloadRepresentation(rep *Representation, assetPath string, w io.Writer) error {
+	if rep.Content.URLTemplate == "" {
+		return fmt.Errorf("%s representation has no content", rep.Representation)
+	}
+	contentURL := strings.Replace(rep.Content.URLTemplate, "{+asset_path}", assetPath, 1)
+	req, err := c.newRequest("GET", contentURL, nil)
+	if err != nil {
+		return err
+	}
+	r, err := c.send(req)
+	if err != nil {
+		return err
+	}
+	defer r.Body.Close()
+
+	switch r.StatusCode {
+	case http.StatusOK:
+		_, err = io.Copy(w, r.Body)
+		return err
+	case http.StatusAccepted:
+		return errDownloadNotReady
+	}
+	_, err = handleResponse(r)
+	if err == nil {
+		err = errors.New("unexpected representation response: " + r.Status)
+	}
+	return err
+}
@@ -0,0 +1,80 @@
+package box
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetThumbnail(t *testing.T) {
+	var requests int
+	server, client := newTestHandlerClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/files/42/thumbnail.png" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		assert.Equal(t, "160", r.URL.Query().Get("min_width"), "Minimum width should be requested")
+		assert.Equal(t, "", r.URL.Query().Get("max_width"), "Unset bounds should not be sent")
+		requests++
+		if requests == 1 {
+			// The thumbnail is still being generated.
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		fmt.Fprint(w, "PNG")
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	err := client.GetThumbnail("42", ThumbnailPNG, &ThumbnailOptions{MinWidth: 160, MinHeight: 160}, &buf)
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, "PNG", buf.String(), "Thumbnail should be written once generated")
+	assert.Equal(t, 2, requests, "Pending thumbnail should be requested again")
+}
+
+func TestRepresentations(t *testing.T) {
+	var hints string
+	var polls int
+	server, client := newTestHandlerClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		baseURL := "http://" + r.Host
+		switch r.URL.Path {
+		case "/files/42":
+			hints = r.Header.Get("X-Rep-Hints")
+			fmt.Fprintf(w, `{"type": "file", "id": "42", "representations": {"entries": [{"representation": "extracted_text",
+				"info": {"url": "%s/info/42"}, "status": {"state": "none"},
+				"content": {"url_template": "%s/reps/42/extracted_text/content/{+asset_path}"}}]}}`, baseURL, baseURL)
+		case "/info/42":
+			polls++
+			fmt.Fprintf(w, `{"representation": "extracted_text", "info": {"url": "%s/info/42"}, "status": {"state": "success"},
+				"content": {"url_template": "%s/reps/42/extracted_text/content/{+asset_path}"}}`, baseURL, baseURL)
+		case "/reps/42/extracted_text/content/":
+			fmt.Fprint(w, "Hello, world")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	reps, err := client.GetRepresentations("42", RepresentationExtractedText, "jpg?dimensions=320x320")
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, "[extracted_text][jpg?dimensions=320x320]", hints, "Hints should be sent in brackets")
+	assert.Len(t, reps, 1, "Representations should be decoded")
+	assert.Equal(t, RepresentationStateNone, reps[0].Status.State, "Status should be decoded")
+
+	rep, err := client.WaitForRepresentation(&reps[0])
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, RepresentationStateSuccess, rep.Status.State, "Generated representation should be returned")
+	assert.Equal(t, 1, polls, "Info URL should be polled until the representation is ready")
+
+	var buf bytes.Buffer
+	err = client.DownloadRepresentation(rep, "", &buf)
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, "Hello, world", buf.String(), "Content should be downloaded from the URL template")
+
+	_, err = client.WaitForRepresentation(&Representation{Representation: "pdf", Status: RepresentationStatus{State: RepresentationStateError}})
+	assert.Error(t, err, "Failed representation should not be waited for")
+}
@@ -0,0 +1,124 @@
+package box
+
+import (
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how requests that fail with a transient error are
+// retried. Rate limited (429) responses are retried for every request whose
+// body can be sent again, as Box did not process the request. Server error
+// (5xx) responses and network errors are retried only for idempotent
+// requests, since Box may have processed the request anyway.
+type RetryPolicy struct {
+	MaxRetries int           // How many times a request is retried after the first attempt.
+	BaseDelay  time.Duration // The delay before the first retry, doubled after every attempt.
+	MaxDelay   time.Duration // The upper bound for the delay between attempts.
+}
+
+// DefaultRetryPolicy is the RetryPolicy used by clients created by NewClient
+// unless WithRetryPolicy is given.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 5,
+	BaseDelay:  500 * time.Millisecond,
+	MaxDelay:   30 * time.Second,
+}
+
+// NoRetries is a RetryPolicy that never retries.
+var NoRetries = RetryPolicy{}
+
+// delay returns how long to wait before retry number attempt, starting at 1.
+// The exponential backoff is jittered so that clients that failed together do
+// not retry together.
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < attempt && (p.MaxDelay <= 0 || d < p.MaxDelay); i++ {
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// send sends req, retrying it according to the client's retry policy. The
+// response of the last attempt is returned, whatever its status.
+func (c *client) send(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		r, err := c.sendOnce(req)
+		if attempt >= c.retryPolicy.MaxRetries || !canResend(req) {
+			return r, err
+		}
+
+		var delay time.Duration
+		switch {
+		case err != nil:
+			if !isIdempotent(req.Method) || c.requestContext().Err() != nil {
+				return r, err
+			}
+			delay = c.retryPolicy.delay(attempt + 1)
+		case r.StatusCode == http.StatusTooManyRequests || r.StatusCode >= 500 && isIdempotent(req.Method):
+			delay = c.retryPolicy.delay(attempt + 1)
+			if seconds, err := strconv.Atoi(r.Header.Get("Retry-After")); err == nil {
+				delay = time.Duration(seconds) * time.Second
+			}
+			io.Copy(ioutil.Discard, r.Body)
+			r.Body.Close()
+		default:
+			return r, nil
+		}
+
+		if err := c.sleep(delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// sendOnce sends req once it is admitted by the governor for its host. The
+// request counts as in flight until its response body is closed.
+func (c *client) sendOnce(req *http.Request) (*http.Response, error) {
+	governor := c.governor(req)
+	if governor == nil {
+		return c.client.Do(req)
+	}
+
+	release, err := governor.Acquire(c.requestContext())
+	if err != nil {
+		return nil, err
+	}
+	r, err := c.client.Do(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	r.Body = &releaseOnClose{ReadCloser: r.Body, release: release}
+	return r, nil
+}
+
+// canResend reports whether the body of req can be sent again.
+func canResend(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package box
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryTransientErrors(t *testing.T) {
+	attempts := 0
+	server, client := newTestHandlerClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		switch attempts {
+		case 1:
+			w.WriteHeader(http.StatusBadGateway)
+		case 2:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			fmt.Fprintln(w, `{"id": "1234"}`)
+		}
+	}))
+	defer server.Close()
+	client.retryPolicy = RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond}
+
+	user, err := client.GetCurrentUser()
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, "1234", user.ID, "ID should be \"1234\"")
+	assert.Equal(t, 3, attempts, "Request should be sent 3 times")
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	attempts := 0
+	server, client := newTestHandlerClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	client.retryPolicy = RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond}
+
+	_, err := client.GetCurrentUser()
+	assert.True(t, IsServerError(err), "Error should be a server error")
+	assert.Equal(t, 3, attempts, "Request should be sent 3 times")
+}
+
+func TestRetryResendsUploadBody(t *testing.T) {
+	var contents []string
+	server, client := newTestHandlerClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		data, _ := ioutil.ReadAll(file)
+		contents = append(contents, string(data))
+		if len(contents) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintln(w, `{"total_count": 1, "entries": [{"id": "1234"}]}`)
+	}))
+	defer server.Close()
+	client.retryPolicy = RetryPolicy{MaxRetries: 1, BaseDelay: time.Millisecond}
+
+	srcPath, data := writeTestFile(t, 100)
+	defer os.RemoveAll(filepath.Dir(srcPath))
+
+	file, err := client.UploadFile(srcPath, "0")
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, "1234", file.ID, "ID should be \"1234\"")
+	assert.Equal(t, []string{string(data), string(data)}, contents, "Whole file should be sent on both attempts")
+}
+
+func TestRetryOnlyIdempotentServerErrors(t *testing.T) {
+	attempts := 0
+	server, client := newTestHandlerClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+	client.retryPolicy = RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond}
+
+	_, err := client.CreateFolder("new", "0")
+	assert.True(t, IsServerError(err), "Error should be a server error")
+	assert.Equal(t, 1, attempts, "POST that Box may have processed should not be sent again")
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: time.Second, MaxDelay: 4 * time.Second}
+	for attempt, max := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second} {
+		delay := policy.delay(attempt + 1)
+		assert.True(t, delay >= max/2 && delay <= max, "Delay %v should be between %v and %v", delay, max/2, max)
+	}
+}
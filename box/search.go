@@ -0,0 +1,102 @@
+package box
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// MaxSearchPageSize is the largest page of search results Box returns.
+	MaxSearchPageSize = 200
+
+	SearchContentName        = "name"
+	SearchContentDescription = "description"
+	SearchContentFileContent = "file_content"
+	SearchContentComments    = "comments"
+	SearchContentTags        = "tags"
+)
+
+// SearchOptions narrows down the items returned by Search. Zero values do not
+// restrict the results.
+type SearchOptions struct {
+	Type              string    // Only return items of this type, e.g. TypeFile.
+	FileExtensions    []string  // Only return files with these extensions, without the dot.
+	AncestorFolderIDs []string  // Only return items inside these folders.
+	ContentTypes      []string  // Only match the query against these parts of items, e.g. SearchContentName.
+	CreatedAfter      time.Time // Only return items created at or after this time.
+	CreatedBefore     time.Time // Only return items created at or before this time.
+	UpdatedAfter      time.Time // Only return items updated at or after this time.
+	UpdatedBefore     time.Time // Only return items updated at or before this time.
+	MinSize           int64     // Only return items of at least this many bytes.
+	MaxSize           int64     // Only return items of at most this many bytes.
+	OwnerUserIDs      []string  // Only return items owned by these users.
+	Fields            []string  // The fields to return for each item.
+	Limit             int       // The number of items per page, at most MaxSearchPageSize.
+}
+
+// Search returns an iterator over the pages of items that match query, most
+// relevant first. opts may be nil to search everything.
+func (c *client) Search(query string, opts *SearchOptions) *ItemIterator {
+	if opts == nil {
+		opts = &SearchOptions{}
+	}
+	limit := opts.Limit
+	if limit <= 0 || limit > MaxSearchPageSize {
+		limit = MaxSearchPageSize
+	}
+
+	it := c.newItemIterator("", "/search", &ItemsOptions{
+		Fields:    opts.Fields,
+		Limit:     limit,
+		UseOffset: true,
+	})
+	it.params = opts.params(query)
+	return it
+}
+
+func (opts *SearchOptions) params(query string) url.Values {
+	params := url.Values{}
+	if query != "" {
+		params.Set("query", query)
+	}
+	if opts.Type != "" {
+		params.Set("type", opts.Type)
+	}
+	setList(params, "file_extensions", opts.FileExtensions)
+	setList(params, "ancestor_folder_ids", opts.AncestorFolderIDs)
+	setList(params, "content_types", opts.ContentTypes)
+	setList(params, "owner_user_ids", opts.OwnerUserIDs)
+	setRange(params, "created_at_range", formatSearchTime(opts.CreatedAfter), formatSearchTime(opts.CreatedBefore))
+	setRange(params, "updated_at_range", formatSearchTime(opts.UpdatedAfter), formatSearchTime(opts.UpdatedBefore))
+	setRange(params, "size_range", formatSearchSize(opts.MinSize), formatSearchSize(opts.MaxSize))
+	return params
+}
+
+func setList(params url.Values, key string, values []string) {
+	if len(values) > 0 {
+		params.Set(key, strings.Join(values, ","))
+	}
+}
+
+// setRange sets a range parameter, in which either bound may be left empty.
+func setRange(params url.Values, key, lower, upper string) {
+	if lower != "" || upper != "" {
+		params.Set(key, lower+","+upper)
+	}
+}
+
+func formatSearchTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func formatSearchSize(size int64) string {
+	if size <= 0 {
+		return ""
+	}
+	return strconv.FormatInt(size, 10)
+}
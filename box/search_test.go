@@ -0,0 +1,55 @@
+package box
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearch(t *testing.T) {
+	var queries []url.Values
+	server, client := newTestHandlerClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/search" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		queries = append(queries, r.URL.Query())
+		if r.URL.Query().Get("offset") == "0" {
+			fmt.Fprintln(w, `{"total_count": 3, "entries": [{"type": "file", "id": "1", "name": "report.pdf"},
+				{"type": "folder", "id": "2", "name": "reports"}]}`)
+		} else {
+			fmt.Fprintln(w, `{"total_count": 3, "entries": [{"type": "file", "id": "3", "name": "old report.pdf"}]}`)
+		}
+	}))
+	defer server.Close()
+
+	it := client.Search("report", &SearchOptions{
+		FileExtensions:    []string{"pdf", "docx"},
+		AncestorFolderIDs: []string{"42"},
+		UpdatedAfter:      time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		MaxSize:           1 << 20,
+		Limit:             2,
+	})
+	var files, folders int
+	for it.Next() {
+		files += len(it.Page().Files)
+		folders += len(it.Page().Folders)
+	}
+	assert.NoError(t, it.Err(), "Iteration should not fail")
+	assert.Equal(t, 2, files, "Files from every page should be returned")
+	assert.Equal(t, 1, folders, "Folders should be returned")
+	assert.Len(t, queries, 2, "Two pages should be requested")
+
+	query := queries[1]
+	assert.Equal(t, "report", query.Get("query"), "Query should be sent with every page")
+	assert.Equal(t, "pdf,docx", query.Get("file_extensions"), "Extensions should be joined")
+	assert.Equal(t, "42", query.Get("ancestor_folder_ids"), "Ancestors should be sent")
+	assert.Equal(t, "2026-01-01T00:00:00Z,", query.Get("updated_at_range"), "Open ended range should be sent")
+	assert.Equal(t, ",1048576", query.Get("size_range"), "Size range should be sent")
+	assert.Equal(t, "", query.Get("created_at_range"), "Unset range should not be sent")
+	assert.Equal(t, "2", query.Get("offset"), "Second page should start after the first")
+}
@@ -0,0 +1,96 @@
+package box
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+const (
+	SharedLinkAccessOpen          = "open"
+	SharedLinkAccessCompany       = "company"
+	SharedLinkAccessCollaborators = "collaborators"
+)
+
+// SetFileSharedLink creates a shared link for the file id, or updates the
+// existing one, with settings. settings may be nil to use the defaults.
+func (c *client) SetFileSharedLink(id string, settings *SharedLinkSettings) (*SharedLink, error) {
+	if settings == nil {
+		settings = &SharedLinkSettings{}
+	}
+	return c.setSharedLink("/files/"+id, settings)
+}
+
+func (c *client) RemoveFileSharedLink(id string) error {
+	_, err := c.setSharedLink("/files/"+id, nil)
+	return err
+}
+
+// SetFolderSharedLink creates a shared link for the folder id, or updates the
+// existing one, as for SetFileSharedLink.
+func (c *client) SetFolderSharedLink(id string, settings *SharedLinkSettings) (*SharedLink, error) {
+	if settings == nil {
+		settings = &SharedLinkSettings{}
+	}
+	return c.setSharedLink("/folders/"+id, settings)
+}
+
+func (c *client) RemoveFolderSharedLink(id string) error {
+	_, err := c.setSharedLink("/folders/"+id, nil)
+	return err
+}
+
+// MarshalJSON encodes s, sending null for a password or an expiry to clear,
+// which Box needs to remove them.
+func (s SharedLinkSettings) MarshalJSON() ([]byte, error) {
+	settings := struct {
+		Access      string                      `json:"access,omitempty"`
+		Password    interface{}                 `json:"password,omitempty"`
+		UnsharedAt  interface{}                 `json:"unshared_at,omitempty"`
+		Permissions *SharedLinkPermissionUpdate `json:"permissions,omitempty"`
+	}{Access: s.Access, Permissions: s.Permissions}
+	if s.Password != "" {
+		settings.Password = s.Password
+	} else if s.ClearPassword {
+		settings.Password = json.RawMessage("null")
+	}
+	if s.UnsharedAt != nil {
+		settings.UnsharedAt = s.UnsharedAt
+	} else if s.ClearExpiry {
+		settings.UnsharedAt = json.RawMessage("null")
+	}
+	return json.Marshal(settings)
+}
+
+// setSharedLink updates the shared link of the item at endpointPath with
+// settings, removing it if settings is nil, and returns the resulting link.
+func (c *client) setSharedLink(endpointPath string, settings *SharedLinkSettings) (*SharedLink, error) {
+	// Box creates a link with the default settings for an empty object and
+	// removes the link for null.
+	var update struct {
+		SharedLink interface{} `json:"shared_link"`
+	}
+	if settings != nil {
+		update.SharedLink = settings
+	}
+	updateJSON, err := json.Marshal(update)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.Put(endpointPath+"?fields=shared_link", "application/json", bytes.NewReader(updateJSON), false)
+	if err != nil {
+		return nil, err
+	}
+	var item struct {
+		SharedLink *SharedLink `json:"shared_link"`
+	}
+	err = json.Unmarshal(body, &item)
+	if err != nil {
+		return nil, err
+	}
+	if settings != nil && item.SharedLink == nil {
+		return nil, errors.New("shared link was not created")
+	}
+	return item.SharedLink, nil
+}
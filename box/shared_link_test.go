@@ -0,0 +1,58 @@
+package box
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSharedLink(t *testing.T) {
+	var body string
+	server, client := newTestHandlerClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PUT" || r.URL.Path != "/files/1234" || r.URL.Query().Get("fields") != "shared_link" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		data, _ := ioutil.ReadAll(r.Body)
+		body = string(data)
+		if body == `{"shared_link":null}` {
+			fmt.Fprintln(w, `{"type": "file", "id": "1234", "shared_link": null}`)
+			return
+		}
+		fmt.Fprintln(w, `{"type": "file", "id": "1234", "shared_link": {"url": "https://app.box.com/s/abc", "access": "open",
+			"permissions": {"can_download": false, "can_preview": true}}}`)
+	}))
+	defer server.Close()
+
+	link, err := client.SetFileSharedLink("1234", nil)
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, "https://app.box.com/s/abc", link.URL, "URL should be returned")
+	assert.Equal(t, `{"shared_link":{}}`, body, "Default settings should be sent")
+
+	expires := time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)
+	_, err = client.SetFileSharedLink("1234", &SharedLinkSettings{
+		Access:      SharedLinkAccessOpen,
+		Password:    "secret",
+		UnsharedAt:  &expires,
+		Permissions: &SharedLinkPermissionUpdate{CanDownload: false},
+	})
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, `{"shared_link":{"access":"open","password":"secret","unshared_at":"2026-12-31T00:00:00Z","permissions":{"can_download":false}}}`,
+		body, "Settings should be sent")
+
+	_, err = client.SetFileSharedLink("1234", &SharedLinkSettings{ClearPassword: true, ClearExpiry: true})
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, `{"shared_link":{"password":null,"unshared_at":null}}`, body, "Clearing should send null")
+
+	_, err = client.SetFileSharedLink("1234", &SharedLinkSettings{Permissions: &SharedLinkPermissionUpdate{CanDownload: true}})
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, `{"shared_link":{"permissions":{"can_download":true}}}`, body, "Downloads should be allowed again")
+
+	err = client.RemoveFileSharedLink("1234")
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, `{"shared_link":null}`, body, "Removing should send null")
+}
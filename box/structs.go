@@ -6,68 +6,507 @@ import (
 )
 
 type User struct {
-	ID    string `json:"id"`
-	Name  string `json:"name"`
-	Login string `json:"login"`
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Login       string `json:"login"`
+	Role        string `json:"role"`         // The user's role in the enterprise, e.g. "user" or "admin".
+	Status      string `json:"status"`       // Whether the user is active, inactive or cannot delete or edit content.
+	SpaceAmount int64  `json:"space_amount"` // The user's storage quota in bytes.
+	SpaceUsed   int64  `json:"space_used"`   // The storage used by the user's files in bytes.
 }
 
-type File struct {
-	ID                string     `json:"id"`                  // Box’s unique string identifying this file.
-	SequenceID        string     `json:"sequence_id"`         // A unique ID for use with the /events endpoint.
-	ETag              string     `json:"etag"`                // A unique string identifying the version of this file.
-	SHA1              string     `json:"sha1"`                // The sha1 hash of this file.
-	Name              string     `json:"name"`                // The name of this file.
-	Description       string     `json:"description"`         // The description of this file.
-	Size              int        `json:"size"`                // Size of this file in bytes.
-	PathCollection    Collection `json:"path_collection"`     // The path of folders to this item, starting at the root.
-	CreatedAt         time.Time  `json:"created_at"`          // When this file was created on Box’s servers.
-	ModifiedAt        time.Time  `json:"modified_at"`         // When this file was last updated on the Box servers.
-	ContentCreatedAt  time.Time  `json:"content_created_at"`  // When the content of this file was created.
-	ContentModifiedAt time.Time  `json:"content_modified_at"` // When the content of this file was last modified.
-	CreatedBy         User       `json:"created_by"`          // The user who first created file.
-	ModifiedBy        User       `json:"modified_by"`         // The user who last updated this file.
-	OwnedBy           User       `json:"owned_by"`            // The user who owns this file.
-	Parent            *Folder    `json:"parent"`              // The folder containing this file.
-	ItemStatus        string     `json:"item_status"`         // Whether this item is deleted or not.
-	VersionNumber     string     `json:"version_number"`      // The version of the file.
-	CommentCount      int        `json:"comment_count"`       // The number of comments on a file.
-	Tags              []string   `json:"tags"`                // All tags applied to this file.
-	Extension         string     `json:"extension"`           // Indicates the suffix, when available, on the file.
+type UserCollection struct {
+	Count   int    `json:"total_count"`
+	Entries []User `json:"entries"`
+	Limit   int    `json:"limit"`
+	Offset  int    `json:"offset"`
 }
 
-type Folder struct {
-	ID                string     `json:"id"`                  // The folder’s ID.
-	SequenceID        string     `json:"sequence_id"`         // A unique ID for use with the /events endpoint.
-	ETag              string     `json:"etag"`                // A unique string identifying the version of this folder.
-	Name              string     `json:"name"`                // The name of this folder.
-	Description       string     `json:"description"`         // The description of this folder.
-	Size              int        `json:"size"`                // Size of this file in bytes.
-	PathCollection    Collection `json:"path_collection"`     // The path of folders to this item, starting at the root.
-	CreatedAt         time.Time  `json:"created_at"`          // The time the folder was created.
-	ModifiedAt        time.Time  `json:"modified_at"`         // The time the folder or its contents were last modified.
-	ContentCreatedAt  time.Time  `json:"content_created_at"`  // The time the folder or its contents were originally created (according to the uploader).
-	ContentModifiedAt time.Time  `json:"content_modified_at"` // The time the folder or its contents were last modified (according to the uploader).
-	CreatedBy         User       `json:"created_by"`          // The user who created this folder.
-	ModifiedBy        User       `json:"modified_by"`         // The user who last modified this folder.
-	OwnedBy           User       `json:"owned_by"`            // The user who owns this file.
-	Parent            *Folder    `json:"parent"`              // The folder that contains this one.
-	ItemStatus        string     `json:"item_status"`         // Whether this item is deleted or not.
-	Tags              []string   `json:"tags"`                // All tags applied to this file.
-	HasCollaborations bool       `json:"has_collaborations"`  // Whether this folder has any collaborators.
-	SyncStatus        string     `json:"sync_status"`         // Whether this folder will be synced by the Box sync clients or not. Can be
+// UserAttributes are the settings of an enterprise user given when creating
+// or updating them. Empty settings are left unchanged by an update.
+type UserAttributes struct {
+	Login       string `json:"login,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Role        string `json:"role,omitempty"`
+	Status      string `json:"status,omitempty"`
+	SpaceAmount int64  `json:"space_amount,omitempty"` // The storage quota in bytes, or -1 for unlimited.
 }
 
-type Collection struct {
+// OwnedContentTransfer is the body of a request to move the content owned by
+// a user to another user.
+type OwnedContentTransfer struct {
+	OwnedBy ItemReference `json:"owned_by"`
+}
+
+type Group struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Provenance  string    `json:"provenance"`  // The system that manages the group, if it is synced from one.
+	CreatedAt   time.Time `json:"created_at"`  // When this group was created.
+	ModifiedAt  time.Time `json:"modified_at"` // When this group was last modified.
+}
+
+type GroupCollection struct {
+	Count   int     `json:"total_count"`
+	Entries []Group `json:"entries"`
+	Limit   int     `json:"limit"`
+	Offset  int     `json:"offset"`
+}
+
+type GroupAttributes struct {
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// GroupMembership makes a user a member of a group.
+type GroupMembership struct {
+	ID         string    `json:"id"`
+	User       User      `json:"user"`        // The member.
+	Group      Group     `json:"group"`       // The group the user belongs to.
+	Role       string    `json:"role"`        // Either "member" or "admin".
+	CreatedAt  time.Time `json:"created_at"`  // When the user was added to the group.
+	ModifiedAt time.Time `json:"modified_at"` // When this membership was last modified.
+}
+
+type GroupMembershipCollection struct {
 	Count   int               `json:"total_count"`
-	Entries []json.RawMessage `json:"entries"`
+	Entries []GroupMembership `json:"entries"`
 	Limit   int               `json:"limit"`
 	Offset  int               `json:"offset"`
 }
 
+type GroupMembershipAttributes struct {
+	User  ItemReference `json:"user"`
+	Group ItemReference `json:"group"`
+	Role  string        `json:"role,omitempty"`
+}
+
+type File struct {
+	ID                string          `json:"id"`                  // Box’s unique string identifying this file.
+	SequenceID        string          `json:"sequence_id"`         // A unique ID for use with the /events endpoint.
+	ETag              string          `json:"etag"`                // A unique string identifying the version of this file.
+	SHA1              string          `json:"sha1"`                // The sha1 hash of this file.
+	Name              string          `json:"name"`                // The name of this file.
+	Description       string          `json:"description"`         // The description of this file.
+	Size              int             `json:"size"`                // Size of this file in bytes.
+	PathCollection    Collection      `json:"path_collection"`     // The path of folders to this item, starting at the root.
+	CreatedAt         time.Time       `json:"created_at"`          // When this file was created on Box’s servers.
+	ModifiedAt        time.Time       `json:"modified_at"`         // When this file was last updated on the Box servers.
+	ContentCreatedAt  time.Time       `json:"content_created_at"`  // When the content of this file was created.
+	ContentModifiedAt time.Time       `json:"content_modified_at"` // When the content of this file was last modified.
+	CreatedBy         User            `json:"created_by"`          // The user who first created file.
+	ModifiedBy        User            `json:"modified_by"`         // The user who last updated this file.
+	OwnedBy           User            `json:"owned_by"`            // The user who owns this file.
+	Parent            *Folder         `json:"parent"`              // The folder containing this file.
+	ItemStatus        string          `json:"item_status"`         // Whether this item is deleted or not.
+	TrashedAt         *time.Time      `json:"trashed_at"`          // When this item was moved to the trash, or nil.
+	PurgedAt          *time.Time      `json:"purged_at"`           // When this item will be permanently deleted from the trash, or nil.
+	VersionNumber     string          `json:"version_number"`      // The version of the file.
+	FileVersion       *FileVersion    `json:"file_version"`        // The current version of the file.
+	CommentCount      int             `json:"comment_count"`       // The number of comments on a file.
+	Tags              []string        `json:"tags"`                // All tags applied to this file.
+	Extension         string          `json:"extension"`           // Indicates the suffix, when available, on the file.
+	SharedLink        *SharedLink     `json:"shared_link"`         // The shared link of this file, or nil.
+	Lock              *Lock           `json:"lock"`                // The lock on this file, or nil.
+	Metadata          MetadataByScope `json:"metadata"`            // The requested metadata instances on this file, by scope & template.
+}
+
+type Folder struct {
+	ID                string          `json:"id"`                  // The folder’s ID.
+	SequenceID        string          `json:"sequence_id"`         // A unique ID for use with the /events endpoint.
+	ETag              string          `json:"etag"`                // A unique string identifying the version of this folder.
+	Name              string          `json:"name"`                // The name of this folder.
+	Description       string          `json:"description"`         // The description of this folder.
+	Size              int             `json:"size"`                // Size of this file in bytes.
+	PathCollection    Collection      `json:"path_collection"`     // The path of folders to this item, starting at the root.
+	CreatedAt         time.Time       `json:"created_at"`          // The time the folder was created.
+	ModifiedAt        time.Time       `json:"modified_at"`         // The time the folder or its contents were last modified.
+	ContentCreatedAt  time.Time       `json:"content_created_at"`  // The time the folder or its contents were originally created (according to the uploader).
+	ContentModifiedAt time.Time       `json:"content_modified_at"` // The time the folder or its contents were last modified (according to the uploader).
+	CreatedBy         User            `json:"created_by"`          // The user who created this folder.
+	ModifiedBy        User            `json:"modified_by"`         // The user who last modified this folder.
+	OwnedBy           User            `json:"owned_by"`            // The user who owns this file.
+	Parent            *Folder         `json:"parent"`              // The folder that contains this one.
+	ItemStatus        string          `json:"item_status"`         // Whether this item is deleted or not.
+	TrashedAt         *time.Time      `json:"trashed_at"`          // When this item was moved to the trash, or nil.
+	PurgedAt          *time.Time      `json:"purged_at"`           // When this item will be permanently deleted from the trash, or nil.
+	Tags              []string        `json:"tags"`                // All tags applied to this file.
+	HasCollaborations bool            `json:"has_collaborations"`  // Whether this folder has any collaborators.
+	SyncStatus        string          `json:"sync_status"`         // Whether this folder will be synced by the Box sync clients or not. Can be
+	SharedLink        *SharedLink     `json:"shared_link"`         // The shared link of this folder, or nil.
+	Metadata          MetadataByScope `json:"metadata"`            // The requested metadata instances on this folder, by scope & template.
+}
+
+// Representation is a rendition of a file generated by Box, such as a PDF,
+// its extracted text or an image.
+type Representation struct {
+	Representation string                   `json:"representation"` // The format of the representation, e.g. "pdf".
+	Properties     RepresentationProperties `json:"properties"`
+	Info           RepresentationInfo       `json:"info"`
+	Status         RepresentationStatus     `json:"status"`
+	Content        RepresentationContent    `json:"content"`
+}
+
+type RepresentationProperties struct {
+	Dimensions string `json:"dimensions"` // The size of image representations, e.g. "1024x1024".
+	Paged      string `json:"paged"`      // "true" if the representation has one asset per page.
+	Thumb      string `json:"thumb"`      // "true" if the representation is meant as a thumbnail.
+}
+
+type RepresentationInfo struct {
+	URL string `json:"url"` // Where to request the current status of the representation.
+}
+
+type RepresentationStatus struct {
+	State string `json:"state"` // One of the RepresentationState constants.
+}
+
+type RepresentationContent struct {
+	URLTemplate string `json:"url_template"` // The URL of the content, with {+asset_path} to be replaced.
+}
+
+// ThumbnailOptions are the bounds of the size of a thumbnail. Zero values do
+// not bound the size.
+type ThumbnailOptions struct {
+	MinWidth  int
+	MinHeight int
+	MaxWidth  int
+	MaxHeight int
+}
+
+type WebLink struct {
+	ID             string      `json:"id"`              // The ID of this web link.
+	Type           string      `json:"type"`            // Always "web_link".
+	SequenceID     string      `json:"sequence_id"`     // A unique ID for use with the /events endpoint.
+	ETag           string      `json:"etag"`            // A unique string identifying the version of this web link.
+	Name           string      `json:"name"`            // The name of this web link.
+	URL            string      `json:"url"`             // The URL this web link points to.
+	Description    string      `json:"description"`     // The description of this web link.
+	PathCollection Collection  `json:"path_collection"` // The path of folders to this item, starting at the root.
+	CreatedAt      time.Time   `json:"created_at"`      // When this web link was created.
+	ModifiedAt     time.Time   `json:"modified_at"`     // When this web link was last updated.
+	CreatedBy      User        `json:"created_by"`      // The user who created this web link.
+	ModifiedBy     User        `json:"modified_by"`     // The user who last updated this web link.
+	OwnedBy        User        `json:"owned_by"`        // The user who owns this web link.
+	Parent         *Folder     `json:"parent"`          // The folder containing this web link.
+	ItemStatus     string      `json:"item_status"`     // Whether this item is deleted or not.
+	TrashedAt      *time.Time  `json:"trashed_at"`      // When this item was moved to the trash, or nil.
+	PurgedAt       *time.Time  `json:"purged_at"`       // When this item will be permanently deleted from the trash, or nil.
+	SharedLink     *SharedLink `json:"shared_link"`     // The shared link of this web link, or nil.
+}
+
+type WebLinkAttributes struct {
+	URL         string `json:"url"`
+	Parent      Parent `json:"parent"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// WebLinkUpdate holds the attributes of a web link to change. Fields left
+// empty are unchanged.
+type WebLinkUpdate struct {
+	URL         string  `json:"url,omitempty"`
+	Name        string  `json:"name,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Parent      *Parent `json:"parent,omitempty"`
+}
+
+// ItemCollection is a named set of files, folders and web links, such as the
+// user's favorites.
+type ItemCollection struct {
+	ID             string `json:"id"`
+	Type           string `json:"type"` // Always "collection".
+	Name           string `json:"name"`
+	CollectionType string `json:"collection_type"` // "favorites" for the favorites.
+}
+
+type Collection struct {
+	Count      int               `json:"total_count"`
+	Entries    []json.RawMessage `json:"entries"`
+	Limit      int               `json:"limit"`
+	Offset     int               `json:"offset"`
+	NextMarker string            `json:"next_marker"`
+}
+
+type SharedLink struct {
+	URL               string                `json:"url"`                 // The URL of the shared link.
+	DownloadURL       string                `json:"download_url"`        // A direct download URL, for files only.
+	VanityURL         string                `json:"vanity_url"`          // A custom URL, if one was set.
+	Access            string                `json:"access"`              // Who can use the link: open, company or collaborators.
+	EffectiveAccess   string                `json:"effective_access"`    // The access actually granted, which the enterprise may restrict.
+	UnsharedAt        *time.Time            `json:"unshared_at"`         // When the link expires, or nil.
+	IsPasswordEnabled bool                  `json:"is_password_enabled"` // Whether the link requires a password.
+	Permissions       SharedLinkPermissions `json:"permissions"`         // What users of the link can do.
+	DownloadCount     int                   `json:"download_count"`      // How many times the item was downloaded through the link.
+	PreviewCount      int                   `json:"preview_count"`       // How many times the item was previewed through the link.
+}
+
+type SharedLinkPermissions struct {
+	CanDownload bool `json:"can_download"`
+	CanPreview  bool `json:"can_preview"`
+}
+
+type Lock struct {
+	ID                  string     `json:"id"`                    // The ID of this lock.
+	Type                string     `json:"type"`                  // Always "lock".
+	CreatedBy           User       `json:"created_by"`            // The user who locked the file.
+	CreatedAt           time.Time  `json:"created_at"`            // When the file was locked.
+	ExpiredAt           *time.Time `json:"expired_at"`            // When the lock expires, or nil if it does not.
+	IsDownloadPrevented bool       `json:"is_download_prevented"` // Whether other users are prevented from downloading the file.
+	AppType             string     `json:"app_type"`              // The kind of application that locked the file, if any.
+}
+
+// LockSettings are the settings of a lock to put on a file.
+type LockSettings struct {
+	Access              string     `json:"access"` // Always "lock".
+	ExpiresAt           *time.Time `json:"expires_at,omitempty"`
+	IsDownloadPrevented bool       `json:"is_download_prevented"`
+}
+
+// SharedLinkSettings are the settings of a shared link to create or update.
+// Fields left empty keep their current or default values; ClearPassword and
+// ClearExpiry remove the password and the expiry of an existing link.
+type SharedLinkSettings struct {
+	Access        string                      `json:"access,omitempty"`
+	Password      string                      `json:"password,omitempty"`
+	UnsharedAt    *time.Time                  `json:"unshared_at,omitempty"`
+	Permissions   *SharedLinkPermissionUpdate `json:"permissions,omitempty"`
+	ClearPassword bool                        `json:"-"` // Ignored if Password is set.
+	ClearExpiry   bool                        `json:"-"` // Ignored if UnsharedAt is set.
+}
+
+type SharedLinkPermissionUpdate struct {
+	CanDownload bool `json:"can_download"`
+}
+
+type FileVersion struct {
+	ID         string     `json:"id"`          // The ID of this version.
+	Type       string     `json:"type"`        // Always "file_version".
+	SHA1       string     `json:"sha1"`        // The sha1 hash of this version.
+	Name       string     `json:"name"`        // The name of the file at this version.
+	Size       int64      `json:"size"`        // Size of this version in bytes.
+	CreatedAt  time.Time  `json:"created_at"`  // When this version was uploaded.
+	ModifiedAt time.Time  `json:"modified_at"` // When this version was last updated.
+	ModifiedBy User       `json:"modified_by"` // The user who uploaded this version.
+	TrashedAt  *time.Time `json:"trashed_at"`  // When this version was deleted, or nil.
+	PurgedAt   *time.Time `json:"purged_at"`   // When this version will be or was permanently deleted, or nil.
+}
+
+type FileVersionCollection struct {
+	Count   int           `json:"total_count"`
+	Entries []FileVersion `json:"entries"`
+	Limit   int           `json:"limit"`
+	Offset  int           `json:"offset"`
+}
+
+type Collaboration struct {
+	ID             string       `json:"id"`              // The ID of this collaboration.
+	Type           string       `json:"type"`            // Always "collaboration".
+	Item           *Folder      `json:"item"`            // The folder the collaborator has access to.
+	AccessibleBy   Collaborator `json:"accessible_by"`   // The user or group that has access.
+	InviteEmail    string       `json:"invite_email"`    // The email address invited, if the invitee has no Box account yet.
+	Role           string       `json:"role"`            // The level of access granted, e.g. "editor".
+	Status         string       `json:"status"`          // Whether the collaboration is accepted, pending or rejected.
+	CreatedBy      User         `json:"created_by"`      // The user who created this collaboration.
+	CreatedAt      time.Time    `json:"created_at"`      // When this collaboration was created.
+	ModifiedAt     time.Time    `json:"modified_at"`     // When this collaboration was last modified.
+	ExpiresAt      *time.Time   `json:"expires_at"`      // When this collaboration expires, or nil.
+	AcknowledgedAt *time.Time   `json:"acknowledged_at"` // When the invitee accepted or rejected the collaboration, or nil.
+}
+
+// Collaborator is a user or group that can be given access to a folder. When
+// inviting a user, either the ID or the login identifies them.
+type Collaborator struct {
+	Type  string `json:"type"`            // Either "user" or "group".
+	ID    string `json:"id,omitempty"`    // The ID of the user or group.
+	Name  string `json:"name,omitempty"`  // The name of the user or group.
+	Login string `json:"login,omitempty"` // The email address of the user.
+}
+
+type CollaborationCollection struct {
+	Count   int             `json:"total_count"`
+	Entries []Collaboration `json:"entries"`
+	Limit   int             `json:"limit"`
+	Offset  int             `json:"offset"`
+}
+
+type CollaborationAttributes struct {
+	Item         ItemReference `json:"item"`
+	AccessibleBy Collaborator  `json:"accessible_by"`
+	Role         string        `json:"role"`
+}
+
+type CollaborationUpdate struct {
+	Role   string `json:"role,omitempty"`
+	Status string `json:"status,omitempty"`
+}
+
+type Comment struct {
+	ID             string        `json:"id"`               // The ID of this comment.
+	Type           string        `json:"type"`             // Always "comment".
+	Message        string        `json:"message"`          // The text of the comment.
+	TaggedMessage  string        `json:"tagged_message"`   // The text of the comment with @mentions in their raw form.
+	IsReplyComment bool          `json:"is_reply_comment"` // Whether this comment replies to another comment.
+	Item           ItemReference `json:"item"`             // The file or comment this comment is on.
+	CreatedBy      User          `json:"created_by"`       // The user who wrote this comment.
+	CreatedAt      time.Time     `json:"created_at"`       // When this comment was created.
+	ModifiedAt     time.Time     `json:"modified_at"`      // When this comment was last edited.
+}
+
+type CommentCollection struct {
+	Count   int       `json:"total_count"`
+	Entries []Comment `json:"entries"`
+	Limit   int       `json:"limit"`
+	Offset  int       `json:"offset"`
+}
+
+type CommentAttributes struct {
+	Message string         `json:"message"`
+	Item    *ItemReference `json:"item,omitempty"`
+}
+
+type Task struct {
+	ID                       string                   `json:"id"`                         // The ID of this task.
+	Type                     string                   `json:"type"`                       // Always "task".
+	Item                     *File                    `json:"item"`                       // The file the task is on.
+	Action                   string                   `json:"action"`                     // What the assignees should do: review or complete.
+	Message                  string                   `json:"message"`                    // The description of the task.
+	DueAt                    *time.Time               `json:"due_at"`                     // When the task is due, or nil.
+	IsCompleted              bool                     `json:"is_completed"`               // Whether the task is done.
+	TaskAssignmentCollection TaskAssignmentCollection `json:"task_assignment_collection"` // Who the task is assigned to.
+	CreatedBy                User                     `json:"created_by"`                 // The user who created this task.
+	CreatedAt                time.Time                `json:"created_at"`                 // When this task was created.
+}
+
+type TaskCollection struct {
+	Count   int    `json:"total_count"`
+	Entries []Task `json:"entries"`
+}
+
+type TaskAttributes struct {
+	Item    ItemReference `json:"item"`
+	Action  string        `json:"action,omitempty"`
+	Message string        `json:"message,omitempty"`
+	DueAt   *time.Time    `json:"due_at,omitempty"`
+}
+
+type TaskAssignment struct {
+	ID              string     `json:"id"`               // The ID of this assignment.
+	Type            string     `json:"type"`             // Always "task_assignment".
+	Item            *File      `json:"item"`             // The file the task is on.
+	AssignedTo      User       `json:"assigned_to"`      // The user the task is assigned to.
+	AssignedBy      User       `json:"assigned_by"`      // The user who assigned the task.
+	Message         string     `json:"message"`          // The message left by the assignee when resolving the task.
+	ResolutionState string     `json:"resolution_state"` // Whether the assignee completed, approved or rejected the task.
+	AssignedAt      time.Time  `json:"assigned_at"`      // When the task was assigned.
+	RemindedAt      *time.Time `json:"reminded_at"`      // When the assignee was last reminded, or nil.
+	CompletedAt     *time.Time `json:"completed_at"`     // When the assignee resolved the task, or nil.
+}
+
+type TaskAssignmentCollection struct {
+	Count   int              `json:"total_count"`
+	Entries []TaskAssignment `json:"entries"`
+}
+
+// TaskAssignee identifies the user to assign a task to, either by ID or by
+// login.
+type TaskAssignee struct {
+	ID    string `json:"id,omitempty"`
+	Login string `json:"login,omitempty"`
+}
+
+type TaskAssignmentAttributes struct {
+	Task     ItemReference `json:"task"`
+	AssignTo TaskAssignee  `json:"assign_to"`
+}
+
+type TaskAssignmentUpdate struct {
+	Message         string `json:"message,omitempty"`
+	ResolutionState string `json:"resolution_state,omitempty"`
+}
+
+// Metadata is an instance of a metadata template on a file or folder. Keys
+// starting with "$" are set by Box, the others are the template's fields.
+type Metadata map[string]interface{}
+
+// MetadataByScope holds metadata instances keyed by scope, then by template.
+type MetadataByScope map[string]map[string]Metadata
+
+type MetadataCollection struct {
+	Entries []Metadata `json:"entries"`
+	Limit   int        `json:"limit"`
+}
+
+type MetadataTemplate struct {
+	ID          string                  `json:"id"`          // The ID of this template.
+	Type        string                  `json:"type"`        // Always "metadata_template".
+	Scope       string                  `json:"scope"`       // Either "global" or "enterprise_" followed by the enterprise ID.
+	TemplateKey string                  `json:"templateKey"` // The key identifying this template within its scope.
+	DisplayName string                  `json:"displayName"` // The name of this template shown to users.
+	Hidden      bool                    `json:"hidden"`      // Whether this template is hidden from users.
+	Fields      []MetadataTemplateField `json:"fields"`      // The fields of this template.
+}
+
+type MetadataTemplateField struct {
+	ID          string                   `json:"id"`
+	Type        string                   `json:"type"` // One of the MetadataField constants.
+	Key         string                   `json:"key"`
+	DisplayName string                   `json:"displayName"`
+	Description string                   `json:"description"`
+	Hidden      bool                     `json:"hidden"`
+	Options     []MetadataTemplateOption `json:"options"` // The allowed values of enum and multiSelect fields.
+}
+
+type MetadataTemplateOption struct {
+	ID  string `json:"id"`
+	Key string `json:"key"`
+}
+
+type MetadataTemplateCollection struct {
+	Entries    []MetadataTemplate `json:"entries"`
+	Limit      int                `json:"limit"`
+	NextMarker string             `json:"next_marker"`
+}
+
+// MetadataOperation is a JSON Patch operation on a metadata instance. Path
+// is the field to change, e.g. "/instrument".
+type MetadataOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+	From  string      `json:"from,omitempty"`
+}
+
+// MetadataQuery selects the files and folders in a folder tree whose
+// metadata matches a query.
+type MetadataQuery struct {
+	From             string                 `json:"from"`                   // The template to query, as scope.templateKey, e.g. "enterprise_12345.datasets".
+	Query            string                 `json:"query,omitempty"`        // A condition on the template's fields, e.g. "instrument = :instrument".
+	QueryParams      map[string]interface{} `json:"query_params,omitempty"` // The values of the parameters in Query.
+	AncestorFolderID string                 `json:"ancestor_folder_id"`     // The folder to search in, "0" for all files.
+	OrderBy          []MetadataQueryOrder   `json:"order_by,omitempty"`     // How to sort the results.
+	Fields           []string               `json:"fields,omitempty"`       // The fields to return for each item, e.g. "metadata.enterprise_12345.datasets.instrument".
+	Limit            int                    `json:"limit,omitempty"`        // The number of items per page, at most MaxMetadataQueryPageSize.
+	Marker           string                 `json:"marker,omitempty"`
+}
+
+type MetadataQueryOrder struct {
+	FieldKey  string `json:"field_key"`
+	Direction string `json:"direction,omitempty"` // "asc" or "desc".
+}
+
+// ItemReference identifies an item of any type in a request body.
+type ItemReference struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
 type FolderContents struct {
-	ID      string
-	Files   []File
-	Folders []Folder
+	ID       string
+	Files    []File
+	Folders  []Folder
+	WebLinks []WebLink
 }
 
 type Event struct {
@@ -109,3 +548,71 @@ type Attributes struct {
 type Parent struct {
 	ID string `json:"id"`
 }
+
+// ItemUpdate is the body of a request to rename or move a file or folder.
+// Fields left empty are not changed.
+type ItemUpdate struct {
+	Name   string  `json:"name,omitempty"`
+	Parent *Parent `json:"parent,omitempty"`
+}
+
+// PreflightAttributes describe an upload to check before sending its content.
+type PreflightAttributes struct {
+	Name   string  `json:"name,omitempty"`
+	Parent *Parent `json:"parent,omitempty"`
+	Size   int64   `json:"size"`
+}
+
+// ItemCopy is the body of a request to copy a file or folder. The copy keeps
+// the original name if Name is empty.
+type ItemCopy struct {
+	Name   string `json:"name,omitempty"`
+	Parent Parent `json:"parent"`
+}
+
+type UploadSession struct {
+	ID                string                 `json:"id"`                  // The ID of this upload session.
+	Type              string                 `json:"type"`                // Always "upload_session".
+	SessionExpiresAt  time.Time              `json:"session_expires_at"`  // When this session will stop accepting parts.
+	PartSize          int64                  `json:"part_size"`           // The size in bytes every part except the last must have.
+	TotalParts        int                    `json:"total_parts"`         // The number of parts needed to upload the whole file.
+	NumPartsProcessed int                    `json:"num_parts_processed"` // The number of parts Box has received and processed.
+	SessionEndpoints  UploadSessionEndpoints `json:"session_endpoints"`   // URLs for operating on this session.
+}
+
+type UploadSessionEndpoints struct {
+	UploadPart string `json:"upload_part"`
+	Commit     string `json:"commit"`
+	Abort      string `json:"abort"`
+	ListParts  string `json:"list_parts"`
+	Status     string `json:"status"`
+	LogEvent   string `json:"log_event"`
+}
+
+type UploadPart struct {
+	PartID string `json:"part_id"` // The ID Box assigned to this part.
+	Offset int64  `json:"offset"`  // The byte offset of this part within the file.
+	Size   int64  `json:"size"`    // Size of this part in bytes.
+	SHA1   string `json:"sha1"`    // The sha1 hash of this part.
+}
+
+type UploadPartResponse struct {
+	Part UploadPart `json:"part"`
+}
+
+type UploadPartCollection struct {
+	Count   int          `json:"total_count"`
+	Entries []UploadPart `json:"entries"`
+	Limit   int          `json:"limit"`
+	Offset  int          `json:"offset"`
+}
+
+type UploadSessionAttributes struct {
+	FolderID string `json:"folder_id,omitempty"`
+	FileName string `json:"file_name,omitempty"`
+	FileSize int64  `json:"file_size"`
+}
+
+type UploadSessionCommit struct {
+	Parts []UploadPart `json:"parts"`
+}
@@ -0,0 +1,125 @@
+package box
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+)
+
+const (
+	TaskActionReview   = "review"
+	TaskActionComplete = "complete"
+
+	ResolutionStateIncomplete = "incomplete"
+	ResolutionStateCompleted  = "completed"
+	ResolutionStateApproved   = "approved"
+	ResolutionStateRejected   = "rejected"
+)
+
+// GetFileTasks returns the tasks on the file fileID together with their
+// assignments.
+func (c *client) GetFileTasks(fileID string) ([]Task, error) {
+	body, err := c.Get("/files/" + fileID + "/tasks")
+	if err != nil {
+		return nil, err
+	}
+	var collection TaskCollection
+	err = json.Unmarshal(body, &collection)
+	if err != nil {
+		return nil, err
+	}
+	return collection.Entries, nil
+}
+
+// CreateTask creates a task on the file fileID asking its assignees to
+// perform action, one of the TaskAction constants. dueAt may be nil for a
+// task without a due date. The task has no assignees until AssignTask is
+// called.
+func (c *client) CreateTask(fileID, action, message string, dueAt *time.Time) (*Task, error) {
+	attrJSON, err := json.Marshal(TaskAttributes{
+		Item:    ItemReference{Type: TypeFile, ID: fileID},
+		Action:  action,
+		Message: message,
+		DueAt:   dueAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.Post("/tasks", "application/json", bytes.NewReader(attrJSON), false)
+	if err != nil {
+		return nil, err
+	}
+	var task Task
+	err = json.Unmarshal(body, &task)
+	if err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+func (c *client) DeleteTask(id string) error {
+	_, err := c.Delete("/tasks/" + id)
+	return err
+}
+
+func (c *client) GetTaskAssignments(taskID string) ([]TaskAssignment, error) {
+	body, err := c.Get("/tasks/" + taskID + "/assignments")
+	if err != nil {
+		return nil, err
+	}
+	var collection TaskAssignmentCollection
+	err = json.Unmarshal(body, &collection)
+	if err != nil {
+		return nil, err
+	}
+	return collection.Entries, nil
+}
+
+// AssignTask assigns the task taskID to assignee, who is notified by Box.
+func (c *client) AssignTask(taskID string, assignee TaskAssignee) (*TaskAssignment, error) {
+	attrJSON, err := json.Marshal(TaskAssignmentAttributes{
+		Task:     ItemReference{Type: TypeTask, ID: taskID},
+		AssignTo: assignee,
+	})
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.Post("/task_assignments", "application/json", bytes.NewReader(attrJSON), false)
+	if err != nil {
+		return nil, err
+	}
+	return decodeTaskAssignment(body)
+}
+
+// UpdateTaskAssignment sets the resolution state of the assignment id to one
+// of the ResolutionState constants, leaving message for the task creator
+// unless it is empty. Review tasks accept approved and rejected, other tasks
+// completed and incomplete.
+func (c *client) UpdateTaskAssignment(id, resolutionState, message string) (*TaskAssignment, error) {
+	updateJSON, err := json.Marshal(TaskAssignmentUpdate{
+		Message:         message,
+		ResolutionState: resolutionState,
+	})
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.Put("/task_assignments/"+id, "application/json", bytes.NewReader(updateJSON), false)
+	if err != nil {
+		return nil, err
+	}
+	return decodeTaskAssignment(body)
+}
+
+func (c *client) DeleteTaskAssignment(id string) error {
+	_, err := c.Delete("/task_assignments/" + id)
+	return err
+}
+
+func decodeTaskAssignment(body []byte) (*TaskAssignment, error) {
+	var assignment TaskAssignment
+	err := json.Unmarshal(body, &assignment)
+	if err != nil {
+		return nil, err
+	}
+	return &assignment, nil
+}
@@ -0,0 +1,72 @@
+package box
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTasks(t *testing.T) {
+	var method, path, body string
+	server, client := newTestHandlerClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		method, path, body = r.Method, r.URL.Path, string(data)
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/files/42/tasks":
+			fmt.Fprintln(w, `{"total_count": 1, "entries": [{"type": "task", "id": "5", "action": "review",
+				"message": "Please review", "due_at": "2026-11-01T17:00:00Z",
+				"task_assignment_collection": {"total_count": 1, "entries": [{"type": "task_assignment", "id": "6",
+					"assigned_to": {"type": "user", "id": "9", "login": "alice@example.com"}, "resolution_state": "incomplete"}]}}]}`)
+		case r.Method == "POST" && r.URL.Path == "/tasks":
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintln(w, `{"type": "task", "id": "5", "action": "review", "message": "Please review"}`)
+		case r.Method == "POST" && r.URL.Path == "/task_assignments":
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintln(w, `{"type": "task_assignment", "id": "6", "resolution_state": "incomplete"}`)
+		case r.Method == "PUT" && r.URL.Path == "/task_assignments/6":
+			fmt.Fprintln(w, `{"type": "task_assignment", "id": "6", "resolution_state": "approved"}`)
+		case r.Method == "DELETE" && r.URL.Path == "/tasks/5":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tasks, err := client.GetFileTasks("42")
+	assert.NoError(t, err, "Function should not return error")
+	assert.Len(t, tasks, 1, "One task should be listed")
+	assert.Equal(t, time.Date(2026, 11, 1, 17, 0, 0, 0, time.UTC), tasks[0].DueAt.UTC(), "Due date should be decoded")
+	assert.Len(t, tasks[0].TaskAssignmentCollection.Entries, 1, "Assignments should be decoded")
+	assert.Equal(t, "alice@example.com", tasks[0].TaskAssignmentCollection.Entries[0].AssignedTo.Login, "Assignee should be decoded")
+
+	dueAt := time.Date(2026, 11, 1, 17, 0, 0, 0, time.UTC)
+	task, err := client.CreateTask("42", TaskActionReview, "Please review", &dueAt)
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, "5", task.ID, "New task should be returned")
+	assert.Equal(t, `{"item":{"type":"file","id":"42"},"action":"review","message":"Please review","due_at":"2026-11-01T17:00:00Z"}`,
+		body, "Task should be created on the file")
+
+	_, err = client.CreateTask("42", TaskActionComplete, "", nil)
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, `{"item":{"type":"file","id":"42"},"action":"complete"}`, body, "Unset fields should be omitted")
+
+	assignment, err := client.AssignTask("5", TaskAssignee{Login: "alice@example.com"})
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, ResolutionStateIncomplete, assignment.ResolutionState, "New assignment should be incomplete")
+	assert.Equal(t, `{"task":{"type":"task","id":"5"},"assign_to":{"login":"alice@example.com"}}`, body, "Assignee should be identified by login")
+
+	assignment, err = client.UpdateTaskAssignment("6", ResolutionStateApproved, "")
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, ResolutionStateApproved, assignment.ResolutionState, "Assignment should be updated")
+	assert.Equal(t, `{"resolution_state":"approved"}`, body, "Only the resolution state should be sent")
+
+	err = client.DeleteTask("5")
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, "DELETE", method, "Removing should be a DELETE")
+	assert.Equal(t, "/tasks/5", path, "Removing should delete the task")
+}
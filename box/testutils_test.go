@@ -22,3 +22,18 @@ func newTestServerClient(endpointPath, responseBody string) (*httptest.Server, C
 
 	return server, client
 }
+
+// newTestHandlerClient returns a client whose API and upload requests are both
+// served by handler.
+func newTestHandlerClient(handler http.Handler) (*httptest.Server, *client) {
+	server := httptest.NewServer(handler)
+
+	client := &client{
+		client:                 &http.Client{},
+		apiBaseURL:             server.URL,
+		apiUploadBaseURL:       server.URL,
+		chunkedUploadThreshold: DefaultChunkedUploadThreshold,
+	}
+
+	return server, client
+}
@@ -0,0 +1,55 @@
+package box
+
+// TrashItemFields are the fields requested for each item by GetTrashItems
+// when ItemsOptions.Fields is not set.
+var TrashItemFields = []string{
+	"sequence_id", "etag", "sha1", "name", "size", "path_collection",
+	"modified_at", "modified_by", "parent", "item_status", "trashed_at",
+	"purged_at",
+}
+
+// GetTrashItems returns an iterator over the pages of files and folders in
+// the trash. opts may be nil to use the defaults.
+func (c *client) GetTrashItems(opts *ItemsOptions) *ItemIterator {
+	if opts == nil || len(opts.Fields) == 0 {
+		o := ItemsOptions{Fields: TrashItemFields}
+		if opts != nil {
+			o.Limit = opts.Limit
+			o.UseOffset = opts.UseOffset
+		}
+		opts = &o
+	}
+	return c.newItemIterator("", "/folders/trash/items", opts)
+}
+
+// RestoreFile moves the file id out of the trash, into the folder parentID
+// with the given name if they are not empty, or else back where it was.
+func (c *client) RestoreFile(id, parentID, name string) (*File, error) {
+	var file File
+	if err := c.updateItem("POST", "/files/"+id, parentID, name, &file); err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// RestoreFolder moves the folder id and everything in it out of the trash, as
+// for RestoreFile.
+func (c *client) RestoreFolder(id, parentID, name string) (*Folder, error) {
+	var folder Folder
+	if err := c.updateItem("POST", "/folders/"+id, parentID, name, &folder); err != nil {
+		return nil, err
+	}
+	return &folder, nil
+}
+
+// PurgeFile permanently deletes the file id, which must be in the trash.
+func (c *client) PurgeFile(id string) error {
+	_, err := c.Delete("/files/" + id + "/trash")
+	return err
+}
+
+// PurgeFolder permanently deletes the folder id, which must be in the trash.
+func (c *client) PurgeFolder(id string) error {
+	_, err := c.Delete("/folders/" + id + "/trash")
+	return err
+}
@@ -0,0 +1,51 @@
+package box
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrash(t *testing.T) {
+	var method, path, body string
+	server, client := newTestHandlerClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		method, path, body = r.Method, r.URL.Path, string(data)
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/folders/trash/items":
+			fmt.Fprintln(w, `{"total_count": 2, "entries": [
+				{"type": "file", "id": "1", "name": "a.txt", "trashed_at": "2026-10-01T10:00:00-07:00"},
+				{"type": "folder", "id": "2", "name": "docs"}]}`)
+		case r.Method == "POST" && r.URL.Path == "/files/1":
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintln(w, `{"type": "file", "id": "1", "name": "b.txt"}`)
+		case r.Method == "DELETE" && r.URL.Path == "/folders/2/trash":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	it := client.GetTrashItems(nil)
+	assert.True(t, it.Next(), "A page should be returned")
+	page := it.Page()
+	assert.Len(t, page.Files, 1, "One file should be trashed")
+	assert.Len(t, page.Folders, 1, "One folder should be trashed")
+	assert.NotNil(t, page.Files[0].TrashedAt, "Trash time should be set")
+	assert.False(t, it.Next(), "There should be no more pages")
+	assert.NoError(t, it.Err(), "Iteration should not fail")
+
+	file, err := client.RestoreFile("1", "", "b.txt")
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, "b.txt", file.Name, "Name should be \"b.txt\"")
+	assert.Equal(t, `{"name":"b.txt"}`, body, "Restore should keep the parent")
+
+	err = client.PurgeFolder("2")
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, "DELETE", method, "Purge should be a DELETE")
+	assert.Equal(t, "/folders/2/trash", path, "Purge should delete from the trash")
+}
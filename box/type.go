@@ -1,8 +1,20 @@
 package box
 
 const (
-	TypeEvent  = "event"
-	TypeFile   = "file"
-	TypeFolder = "folder"
-	TypeUser   = "user"
+	TypeCollaboration    = "collaboration"
+	TypeCollection       = "collection"
+	TypeComment          = "comment"
+	TypeEvent            = "event"
+	TypeFile             = "file"
+	TypeFileVersion      = "file_version"
+	TypeFolder           = "folder"
+	TypeGroup            = "group"
+	TypeGroupMembership  = "group_membership"
+	TypeLock             = "lock"
+	TypeMetadataTemplate = "metadata_template"
+	TypeTask             = "task"
+	TypeTaskAssignment   = "task_assignment"
+	TypeUploadSession    = "upload_session"
+	TypeWebLink          = "web_link"
+	TypeUser             = "user"
 )
@@ -0,0 +1,226 @@
+package box
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultChunkedUploadThreshold is the file size in bytes at and above
+	// which UploadFile and UploadFileVersion switch from a single multipart
+	// request to a chunked upload session.
+	DefaultChunkedUploadThreshold = 50 << 20
+
+	// MinChunkedUploadSize is the smallest file Box accepts through an upload
+	// session.
+	MinChunkedUploadSize = 20 << 20
+
+	maxCommitAttempts  = 10
+	defaultCommitDelay = time.Second
+)
+
+func (c *client) CreateUploadSession(parentID, fileName string, fileSize int64) (*UploadSession, error) {
+	return c.createUploadSession("/files/upload_sessions", UploadSessionAttributes{
+		FolderID: parentID,
+		FileName: fileName,
+		FileSize: fileSize,
+	})
+}
+
+func (c *client) CreateUploadSessionVersion(fileID string, fileSize int64) (*UploadSession, error) {
+	return c.createUploadSession("/files/"+fileID+"/upload_sessions", UploadSessionAttributes{
+		FileSize: fileSize,
+	})
+}
+
+func (c *client) createUploadSession(endpointPath string, attr UploadSessionAttributes) (*UploadSession, error) {
+	attrJSON, err := json.Marshal(attr)
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.Post(endpointPath, "application/json", bytes.NewReader(attrJSON), true)
+	if err != nil {
+		return nil, err
+	}
+	var session UploadSession
+	err = json.Unmarshal(body, &session)
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (c *client) GetUploadSession(sessionID string) (*UploadSession, error) {
+	req, err := c.newRequest("GET", c.uploadEndpointURL("/files/upload_sessions/"+sessionID), nil)
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	var session UploadSession
+	err = json.Unmarshal(body, &session)
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// UploadPart uploads data as the part of the session's file starting at
+// offset. fileSize is the size of the whole file.
+func (c *client) UploadPart(sessionID string, data []byte, offset, fileSize int64) (*UploadPart, error) {
+	req, err := c.newRequest("PUT", c.uploadEndpointURL("/files/upload_sessions/"+sessionID), bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	digest := sha1.Sum(data)
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, offset+int64(len(data))-1, fileSize))
+	req.Header.Set("Digest", "sha="+base64.StdEncoding.EncodeToString(digest[:]))
+
+	body, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	var resp UploadPartResponse
+	err = json.Unmarshal(body, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp.Part, nil
+}
+
+func (c *client) ListUploadParts(sessionID string) ([]UploadPart, error) {
+	var parts []UploadPart
+	for {
+		req, err := c.newRequest("GET", c.uploadEndpointURL("/files/upload_sessions/"+sessionID+
+			"/parts?limit=1000&offset="+strconv.Itoa(len(parts))), nil)
+		if err != nil {
+			return nil, err
+		}
+		body, err := c.do(req)
+		if err != nil {
+			return nil, err
+		}
+		var collection UploadPartCollection
+		err = json.Unmarshal(body, &collection)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, collection.Entries...)
+		if len(collection.Entries) == 0 || len(parts) >= collection.Count {
+			return parts, nil
+		}
+	}
+}
+
+// CommitUploadSession assembles the uploaded parts into a file. sha1 is the
+// hex-encoded sha1 hash of the whole file.
+func (c *client) CommitUploadSession(sessionID, sha1 string, parts []UploadPart) (*File, error) {
+	digest, err := hex.DecodeString(sha1)
+	if err != nil {
+		return nil, err
+	}
+	commitJSON, err := json.Marshal(UploadSessionCommit{Parts: parts})
+	if err != nil {
+		return nil, err
+	}
+
+	// Box answers 202 Accepted while it is still processing parts, in which
+	// case the commit has to be sent again after Retry-After.
+	for attempt := 1; ; attempt++ {
+		req, err := c.newRequest("POST", c.uploadEndpointURL("/files/upload_sessions/"+sessionID+"/commit"),
+			bytes.NewReader(commitJSON))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Digest", "sha="+base64.StdEncoding.EncodeToString(digest))
+		c.setIfMatch(req)
+
+		r, err := c.send(req)
+		if err != nil {
+			return nil, err
+		}
+		if r.StatusCode != http.StatusAccepted {
+			body, err := handleResponse(r)
+			r.Body.Close()
+			if err != nil {
+				return nil, err
+			}
+			return handleUploadResponse(body)
+		}
+		io.Copy(ioutil.Discard, r.Body)
+		r.Body.Close()
+
+		if attempt == maxCommitAttempts {
+			return nil, fmt.Errorf("upload session %s was not ready after %d commit attempts", sessionID, attempt)
+		}
+		delay := defaultCommitDelay
+		if seconds, err := strconv.Atoi(r.Header.Get("Retry-After")); err == nil {
+			delay = time.Duration(seconds) * time.Second
+		}
+		if err := c.sleep(delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (c *client) AbortUploadSession(sessionID string) error {
+	req, err := c.newRequest("DELETE", c.uploadEndpointURL("/files/upload_sessions/"+sessionID), nil)
+	if err != nil {
+		return err
+	}
+	_, err = c.do(req)
+	return err
+}
+
+// uploadChunked reads size bytes from r and uploads them through session,
+// aborting the session if any part of the upload fails.
+func (c *client) uploadChunked(session *UploadSession, r io.Reader, size int64) (*File, error) {
+	file, err := c.uploadParts(session, r, size)
+	if err != nil {
+		c.AbortUploadSession(session.ID)
+		return nil, err
+	}
+	return file, nil
+}
+
+func (c *client) uploadParts(session *UploadSession, r io.Reader, size int64) (*File, error) {
+	if session.PartSize <= 0 {
+		return nil, fmt.Errorf("upload session %s has invalid part size %d", session.ID, session.PartSize)
+	}
+
+	hash := sha1.New()
+	buf := make([]byte, session.PartSize)
+	var parts []UploadPart
+	for offset := int64(0); offset < size; {
+		n := session.PartSize
+		if size-offset < n {
+			n = size - offset
+		}
+		if _, err := io.ReadFull(r, buf[:n]); err != nil {
+			return nil, err
+		}
+		hash.Write(buf[:n])
+
+		part, err := c.UploadPart(session.ID, buf[:n], offset, size)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, *part)
+		offset += n
+	}
+
+	return c.CommitUploadSession(session.ID, hex.EncodeToString(hash.Sum(nil)), parts)
+}
@@ -0,0 +1,119 @@
+package box
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeUploadSessionServer struct {
+	partSize  int64
+	parts     map[int64][]byte
+	committed []byte
+	aborted   bool
+	failPart  bool
+}
+
+func (s *fakeUploadSessionServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == "POST" && (r.URL.Path == "/files/upload_sessions" || r.URL.Path == "/files/1234/upload_sessions"):
+		s.parts = map[int64][]byte{}
+		s.committed = nil
+		fmt.Fprintf(w, `{"id": "sess", "type": "upload_session", "part_size": %d}`, s.partSize)
+	case r.Method == "PUT" && r.URL.Path == "/files/upload_sessions/sess":
+		if s.failPart {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		var start, end, total int64
+		fmt.Sscanf(r.Header.Get("Content-Range"), "bytes %d-%d/%d", &start, &end, &total)
+		data, _ := ioutil.ReadAll(r.Body)
+		digest := sha1.Sum(data)
+		if r.Header.Get("Digest") != "sha="+base64.StdEncoding.EncodeToString(digest[:]) ||
+			int64(len(data)) != end-start+1 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.parts[start] = data
+		fmt.Fprintf(w, `{"part": {"part_id": "%d", "offset": %d, "size": %d, "sha1": "%x"}}`,
+			start, start, len(data), digest)
+	case r.Method == "POST" && r.URL.Path == "/files/upload_sessions/sess/commit":
+		var commit UploadSessionCommit
+		json.NewDecoder(r.Body).Decode(&commit)
+		for _, part := range commit.Parts {
+			s.committed = append(s.committed, s.parts[part.Offset]...)
+		}
+		digest := sha1.Sum(s.committed)
+		if r.Header.Get("Digest") != "sha="+base64.StdEncoding.EncodeToString(digest[:]) {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"total_count": 1, "entries": [{"id": "1234", "sha1": "%x", "size": %d}]}`,
+			digest, len(s.committed))
+	case r.Method == "DELETE" && r.URL.Path == "/files/upload_sessions/sess":
+		s.aborted = true
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func writeTestFile(t *testing.T, size int) (string, []byte) {
+	dir, err := ioutil.TempDir("", "boxtest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := bytes.Repeat([]byte("0123456789"), size/10+1)[:size]
+	srcPath := filepath.Join(dir, "data.bin")
+	if err := ioutil.WriteFile(srcPath, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return srcPath, data
+}
+
+func TestUploadFileChunked(t *testing.T) {
+	fake := &fakeUploadSessionServer{partSize: 16}
+	server, client := newTestHandlerClient(fake)
+	defer server.Close()
+	client.chunkedUploadThreshold = 32
+
+	srcPath, data := writeTestFile(t, 70)
+	defer os.RemoveAll(filepath.Dir(srcPath))
+
+	file, err := client.UploadFile(srcPath, "0")
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, "1234", file.ID, "ID should be \"1234\"")
+	assert.Len(t, fake.parts, 5, "File should be uploaded in 5 parts")
+	assert.Equal(t, data, fake.committed, "Committed content should match the file")
+	digest := sha1.Sum(data)
+	assert.Equal(t, hex.EncodeToString(digest[:]), file.SHA1, "SHA1 should match the file")
+
+	file, err = client.UploadFileVersion("1234", srcPath)
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, "1234", file.ID, "ID should be \"1234\"")
+}
+
+func TestUploadFileChunkedAbortsOnFailure(t *testing.T) {
+	fake := &fakeUploadSessionServer{partSize: 16, failPart: true}
+	server, client := newTestHandlerClient(fake)
+	defer server.Close()
+	client.chunkedUploadThreshold = 32
+
+	srcPath, _ := writeTestFile(t, 70)
+	defer os.RemoveAll(filepath.Dir(srcPath))
+
+	_, err := client.UploadFile(srcPath, "0")
+	assert.Error(t, err, "Function should return error")
+	assert.True(t, fake.aborted, "Session should be aborted")
+}
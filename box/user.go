@@ -1,7 +1,21 @@
 package box
 
 import (
+	"bytes"
 	"encoding/json"
+	"net/url"
+	"strconv"
+)
+
+const (
+	UserRoleUser    = "user"
+	UserRoleCoadmin = "coadmin"
+	UserRoleAdmin   = "admin"
+
+	UserStatusActive                 = "active"
+	UserStatusInactive               = "inactive"
+	UserStatusCannotDeleteEdit       = "cannot_delete_edit"
+	UserStatusCannotDeleteEditUpload = "cannot_delete_edit_upload"
 )
 
 func (c *client) GetCurrentUser() (*User, error) {
@@ -18,3 +32,99 @@ func (c *client) GetCurrentUser() (*User, error) {
 
 	return &user, nil
 }
+
+func (c *client) GetUser(id string) (*User, error) {
+	body, err := c.Get("/users/" + id)
+	if err != nil {
+		return nil, err
+	}
+	return decodeUser(body)
+}
+
+// GetEnterpriseUsers returns the users of the current user's enterprise whose
+// name or login starts with filter, or every user if filter is empty. Only
+// enterprise admins can list users.
+func (c *client) GetEnterpriseUsers(filter string) ([]User, error) {
+	var users []User
+	for {
+		endpointPath := "/users?limit=100&offset=" + strconv.Itoa(len(users))
+		if filter != "" {
+			endpointPath += "&filter_term=" + url.QueryEscape(filter)
+		}
+		body, err := c.Get(endpointPath)
+		if err != nil {
+			return nil, err
+		}
+		var collection UserCollection
+		err = json.Unmarshal(body, &collection)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, collection.Entries...)
+		if len(collection.Entries) == 0 || len(users) >= collection.Count {
+			return users, nil
+		}
+	}
+}
+
+// CreateUser creates a managed user in the current user's enterprise. attr
+// must have a login and a name.
+func (c *client) CreateUser(attr UserAttributes) (*User, error) {
+	attrJSON, err := json.Marshal(attr)
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.Post("/users", "application/json", bytes.NewReader(attrJSON), false)
+	if err != nil {
+		return nil, err
+	}
+	return decodeUser(body)
+}
+
+func (c *client) UpdateUser(id string, attr UserAttributes) (*User, error) {
+	attrJSON, err := json.Marshal(attr)
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.Put("/users/"+id, "application/json", bytes.NewReader(attrJSON), false)
+	if err != nil {
+		return nil, err
+	}
+	return decodeUser(body)
+}
+
+// DeactivateUser stops the user id from logging in while keeping their
+// content, e.g. so that it can be transferred to another user.
+func (c *client) DeactivateUser(id string) (*User, error) {
+	return c.UpdateUser(id, UserAttributes{Status: UserStatusInactive})
+}
+
+// TransferOwnedContent moves every file and folder owned by the user
+// fromUserID into a new folder in the root folder of the user toUserID, and
+// returns that folder. notify emails the source user about the transfer.
+func (c *client) TransferOwnedContent(fromUserID, toUserID string, notify bool) (*Folder, error) {
+	transferJSON, err := json.Marshal(OwnedContentTransfer{OwnedBy: ItemReference{Type: TypeUser, ID: toUserID}})
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.Put("/users/"+fromUserID+"/folders/0?notify="+strconv.FormatBool(notify),
+		"application/json", bytes.NewReader(transferJSON), false)
+	if err != nil {
+		return nil, err
+	}
+	var folder Folder
+	err = json.Unmarshal(body, &folder)
+	if err != nil {
+		return nil, err
+	}
+	return &folder, nil
+}
+
+func decodeUser(body []byte) (*User, error) {
+	var user User
+	err := json.Unmarshal(body, &user)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
@@ -1,6 +1,9 @@
 package box
 
 import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -20,3 +23,62 @@ func TestGetCurrentUser(t *testing.T) {
 	assert.Equal(t, "Example User", user.Name, "Name should be \"Example User\"")
 	assert.Equal(t, "user@example.com", user.Login, "Login should be \"user@example.com\"")
 }
+
+func TestGetEnterpriseUsersPaginates(t *testing.T) {
+	var filters []string
+	server, client := newTestHandlerClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		filters = append(filters, r.URL.Query().Get("filter_term"))
+		if r.URL.Query().Get("offset") == "0" {
+			fmt.Fprintln(w, `{"total_count": 2, "entries": [{"type": "user", "id": "1", "login": "lab1@illinois.edu", "status": "active"}]}`)
+			return
+		}
+		fmt.Fprintln(w, `{"total_count": 2, "entries": [{"type": "user", "id": "2", "login": "lab2@illinois.edu", "status": "inactive"}]}`)
+	}))
+	defer server.Close()
+
+	users, err := client.GetEnterpriseUsers("lab")
+	assert.NoError(t, err, "Function should not return error")
+	assert.Len(t, users, 2, "Users from both pages should be returned")
+	assert.Equal(t, "lab2@illinois.edu", users[1].Login, "Second page should be decoded")
+	assert.Equal(t, UserStatusInactive, users[1].Status, "Status should be decoded")
+	assert.Equal(t, []string{"lab", "lab"}, filters, "Filter should be sent with every page")
+}
+
+func TestCreateAndDeactivateUser(t *testing.T) {
+	var methods, bodies []string
+	server, client := newTestHandlerClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		methods = append(methods, r.Method+" "+r.URL.Path)
+		bodies = append(bodies, string(body))
+		fmt.Fprintln(w, `{"type": "user", "id": "9", "login": "lab3@illinois.edu", "name": "Lab 3", "status": "active"}`)
+	}))
+	defer server.Close()
+
+	user, err := client.CreateUser(UserAttributes{Login: "lab3@illinois.edu", Name: "Lab 3", SpaceAmount: 1 << 30})
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, "9", user.ID, "Created user should be decoded")
+
+	_, err = client.DeactivateUser("9")
+	assert.NoError(t, err, "Function should not return error")
+
+	assert.Equal(t, []string{"POST /users", "PUT /users/9"}, methods, "Users should be created & updated")
+	assert.Equal(t, `{"login":"lab3@illinois.edu","name":"Lab 3","space_amount":1073741824}`, bodies[0], "Only the given settings should be sent")
+	assert.Equal(t, `{"status":"inactive"}`, bodies[1], "Deactivation should only change the status")
+}
+
+func TestTransferOwnedContent(t *testing.T) {
+	var path, notify, body string
+	server, client := newTestHandlerClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		path, notify, body = r.Method+" "+r.URL.Path, r.URL.Query().Get("notify"), string(data)
+		fmt.Fprintln(w, `{"type": "folder", "id": "77", "name": "lab3@illinois.edu - Lab 3's Files and Folders"}`)
+	}))
+	defer server.Close()
+
+	folder, err := client.TransferOwnedContent("9", "1", false)
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, "77", folder.ID, "Folder holding the transferred content should be returned")
+	assert.Equal(t, "PUT /users/9/folders/0", path, "Source user's root folder should be transferred")
+	assert.Equal(t, "false", notify, "Notification setting should be sent")
+	assert.Equal(t, `{"owned_by":{"type":"user","id":"1"}}`, body, "Destination user should be sent")
+}
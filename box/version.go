@@ -0,0 +1,60 @@
+package box
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+// GetFileVersions returns the previous versions of the file fileID, newest
+// first. The current version is not included; it is File.FileVersion.
+func (c *client) GetFileVersions(fileID string) ([]FileVersion, error) {
+	var versions []FileVersion
+	for {
+		body, err := c.Get("/files/" + fileID + "/versions?limit=1000&offset=" + strconv.Itoa(len(versions)))
+		if err != nil {
+			return nil, err
+		}
+		var collection FileVersionCollection
+		err = json.Unmarshal(body, &collection)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, collection.Entries...)
+		if len(collection.Entries) == 0 || len(versions) >= collection.Count {
+			return versions, nil
+		}
+	}
+}
+
+// DownloadFileVersion writes the content of the version versionID of the file
+// fileID to w.
+func (c *client) DownloadFileVersion(fileID, versionID string, w io.Writer) error {
+	_, err := c.downloadContentRange(contentPath(fileID)+"?version="+versionID, w, 0, -1)
+	return err
+}
+
+// PromoteFileVersion makes a copy of the version versionID of the file fileID
+// its current version, and returns the new current version.
+func (c *client) PromoteFileVersion(fileID, versionID string) (*FileVersion, error) {
+	refJSON, err := json.Marshal(ItemReference{Type: TypeFileVersion, ID: versionID})
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.Post("/files/"+fileID+"/versions/current", "application/json", bytes.NewReader(refJSON), false)
+	if err != nil {
+		return nil, err
+	}
+	var version FileVersion
+	err = json.Unmarshal(body, &version)
+	if err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
+
+func (c *client) DeleteFileVersion(fileID, versionID string) error {
+	_, err := c.Delete("/files/" + fileID + "/versions/" + versionID)
+	return err
+}
@@ -0,0 +1,56 @@
+package box
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileVersions(t *testing.T) {
+	var promoted string
+	server, client := newTestHandlerClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/files/1234/versions":
+			// Two versions, served one per page.
+			if r.URL.Query().Get("offset") == "0" {
+				fmt.Fprintln(w, `{"total_count": 2, "entries": [{"type": "file_version", "id": "v2", "size": 20}]}`)
+			} else {
+				fmt.Fprintln(w, `{"total_count": 2, "entries": [{"type": "file_version", "id": "v1", "size": 10}]}`)
+			}
+		case r.Method == "GET" && r.URL.Path == "/files/1234/content":
+			fmt.Fprint(w, "version "+r.URL.Query().Get("version"))
+		case r.Method == "POST" && r.URL.Path == "/files/1234/versions/current":
+			body, _ := ioutil.ReadAll(r.Body)
+			promoted = string(body)
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintln(w, `{"type": "file_version", "id": "v4"}`)
+		case r.Method == "DELETE" && r.URL.Path == "/files/1234/versions/v1":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	versions, err := client.GetFileVersions("1234")
+	assert.NoError(t, err, "Function should not return error")
+	assert.Len(t, versions, 2, "Every page should be collected")
+	assert.Equal(t, "v2", versions[0].ID, "Newest version should be first")
+
+	var buf bytes.Buffer
+	err = client.DownloadFileVersion("1234", "v1", &buf)
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, "version v1", buf.String(), "The requested version should be downloaded")
+
+	version, err := client.PromoteFileVersion("1234", "v1")
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, "v4", version.ID, "The new current version should be returned")
+	assert.Equal(t, `{"type":"file_version","id":"v1"}`, promoted, "The promoted version should be sent")
+
+	err = client.DeleteFileVersion("1234", "v1")
+	assert.NoError(t, err, "Function should not return error")
+}
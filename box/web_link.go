@@ -0,0 +1,69 @@
+package box
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+func (c *client) GetWebLink(id string) (*WebLink, error) {
+	body, err := c.Get("/web_links/" + id)
+	if err != nil {
+		return nil, err
+	}
+	return decodeWebLink(body)
+}
+
+// CreateWebLink creates a web link to url in the folder parentID. Box names
+// the link after url if name is empty.
+func (c *client) CreateWebLink(url, parentID, name, description string) (*WebLink, error) {
+	attrJSON, err := json.Marshal(WebLinkAttributes{
+		URL:         url,
+		Parent:      Parent{ID: parentID},
+		Name:        name,
+		Description: description,
+	})
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.Post("/web_links", "application/json", bytes.NewReader(attrJSON), false)
+	if err != nil {
+		return nil, err
+	}
+	return decodeWebLink(body)
+}
+
+func (c *client) UpdateWebLink(id string, update WebLinkUpdate) (*WebLink, error) {
+	updateJSON, err := json.Marshal(update)
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.Put("/web_links/"+id, "application/json", bytes.NewReader(updateJSON), false)
+	if err != nil {
+		return nil, err
+	}
+	return decodeWebLink(body)
+}
+
+// MoveWebLink moves the web link id into the folder parentID, renaming it to
+// name unless name is empty.
+func (c *client) MoveWebLink(id, parentID, name string) (*WebLink, error) {
+	var webLink WebLink
+	if err := c.updateItem("PUT", "/web_links/"+id, parentID, name, &webLink); err != nil {
+		return nil, err
+	}
+	return &webLink, nil
+}
+
+func (c *client) DeleteWebLink(id string) error {
+	_, err := c.Delete("/web_links/" + id)
+	return err
+}
+
+func decodeWebLink(body []byte) (*WebLink, error) {
+	var webLink WebLink
+	err := json.Unmarshal(body, &webLink)
+	if err != nil {
+		return nil, err
+	}
+	return &webLink, nil
+}
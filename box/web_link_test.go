@@ -0,0 +1,65 @@
+package box
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebLinks(t *testing.T) {
+	var method, path, body string
+	server, client := newTestHandlerClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		method, path, body = r.Method, r.URL.Path, string(data)
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/web_links":
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintln(w, `{"type": "web_link", "id": "5", "name": "Lab wiki", "url": "https://wiki.example.com"}`)
+		case r.Method == "PUT" && r.URL.Path == "/web_links/5":
+			fmt.Fprintln(w, `{"type": "web_link", "id": "5", "name": "Wiki", "url": "https://wiki.example.org"}`)
+		case r.Method == "DELETE" && r.URL.Path == "/web_links/5":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	webLink, err := client.CreateWebLink("https://wiki.example.com", "42", "Lab wiki", "")
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, "https://wiki.example.com", webLink.URL, "New web link should be returned")
+	assert.Equal(t, `{"url":"https://wiki.example.com","parent":{"id":"42"},"name":"Lab wiki"}`, body, "Web link should be created in the folder")
+
+	webLink, err = client.UpdateWebLink("5", WebLinkUpdate{URL: "https://wiki.example.org"})
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, "https://wiki.example.org", webLink.URL, "Updated web link should be returned")
+	assert.Equal(t, `{"url":"https://wiki.example.org"}`, body, "Only the URL should be sent")
+
+	_, err = client.MoveWebLink("5", "43", "Wiki")
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, `{"name":"Wiki","parent":{"id":"43"}}`, body, "Move should set the parent and name")
+
+	err = client.DeleteWebLink("5")
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, "DELETE", method, "Removing should be a DELETE")
+	assert.Equal(t, "/web_links/5", path, "Removing should delete the web link")
+}
+
+func TestGetFolderContentsWebLinks(t *testing.T) {
+	server, client := newTestHandlerClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"entries": [{"type": "file", "id": "1", "name": "a.txt"},
+			{"type": "web_link", "id": "2", "name": "Lab wiki", "url": "https://wiki.example.com"},
+			{"type": "folder", "id": "3", "name": "data"}]}`)
+	}))
+	defer server.Close()
+
+	contents, err := client.GetFolderContents("42")
+	assert.NoError(t, err, "Function should not return error")
+	assert.Len(t, contents.Files, 1, "Files should be listed")
+	assert.Len(t, contents.Folders, 1, "Folders should be listed")
+	assert.Len(t, contents.WebLinks, 1, "Web links should be listed")
+	assert.Equal(t, "https://wiki.example.com", contents.WebLinks[0].URL, "Web link URL should be decoded")
+}
@@ -9,8 +9,12 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	gosync "sync"
+	"time"
 
 	_ "github.com/mattn/go-sqlite3"
+	"golang.org/x/net/context"
+
 	"gitlab.engr.illinois.edu/sp-box/boxsync/box"
 	"gitlab.engr.illinois.edu/sp-box/boxsync/sync"
 )
@@ -20,9 +24,10 @@ var defaultRemoteRootDirectory = "Box Sync"
 var defaultDBLocation = path.Join(os.Getenv("HOME"), ".boxsync_cache.db")
 
 type SyncCache interface {
-	UpdateCache() error
-	HardRefresh() error
-	RescanLocalTree() error
+	UpdateCache(ctx context.Context) error
+	HardRefresh(ctx context.Context) error
+	RescanLocalTree(ctx context.Context) error
+	HandleEvent(ctx context.Context, event box.Event) error
 	//SetEntryInvalid(path string) error
 }
 
@@ -32,6 +37,9 @@ type syncCache struct {
 	localRootDirectory  string
 	remoteRootDirectory string
 	dbLocation          string
+	ctx                 context.Context
+	userID              string        // The syncing user, whose own locks do not stop uploads.
+	mu                  *gosync.Mutex // Serializes local scans and remote events.
 }
 
 type FileCacheEntry struct {
@@ -41,6 +49,8 @@ type FileCacheEntry struct {
 	Valid      sql.NullBool
 	SequenceID sql.NullString
 	ParentID   sql.NullString
+	Inode      sql.NullInt64
+	Locked     sql.NullBool
 }
 
 type FolderCacheEntry struct {
@@ -49,9 +59,10 @@ type FolderCacheEntry struct {
 	Valid      sql.NullBool
 	SequenceID sql.NullString
 	ParentID   sql.NullString
+	Inode      sql.NullInt64
 }
 
-func NewCache(client box.Client) (SyncCache, error) {
+func NewCache(ctx context.Context, client box.Client) (SyncCache, error) {
 	if client == nil {
 		return nil, errors.New("Client cannot be nil")
 	}
@@ -61,23 +72,72 @@ func NewCache(client box.Client) (SyncCache, error) {
 		return nil, err
 	}
 
+	err = createCacheTables(db)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := client.WithContext(ctx).GetCurrentUser()
+	if err != nil {
+		return nil, err
+	}
+
+	cache := syncCache{
+		client:              client,
+		db:                  db,
+		localRootDirectory:  defaultLocalRootDirectory,
+		remoteRootDirectory: defaultRemoteRootDirectory,
+		dbLocation:          defaultDBLocation,
+		ctx:                 context.Background(),
+		userID:              user.ID,
+		mu:                  &gosync.Mutex{},
+	}
+
+	err = cache.HardRefresh(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = cache.UpdateCache(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = cache.withContext(ctx).resumeUploads()
+	if err != nil {
+		return nil, err
+	}
+
+	return &cache, nil
+}
+
+// createCacheTables creates the tables of the cache, emptying the tables of
+// files, folders and web links, which are filled again by a refresh, and the
+// record of skipped uploads.
+func createCacheTables(db *sql.DB) error {
 	sqlStmt := "drop table if exists files;"
+	_, err := db.Exec(sqlStmt)
+	if err != nil {
+		return err
+	}
+
+	sqlStmt = "drop table if exists weblinks;"
 	_, err = db.Exec(sqlStmt)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	sqlStmt = "drop table if exists folders;"
 	_, err = db.Exec(sqlStmt)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	sqlStmt = "pragma foreign_keys=ON;"
 	_, err = db.Exec(sqlStmt)
 	if err != nil {
 		log.Print("Failed enabbling foreign keys")
-		return nil, err
+		return err
 	}
 
 	sqlStmt = `create table folders
@@ -86,13 +146,14 @@ func NewCache(client box.Client) (SyncCache, error) {
 	Valid boolean,
 	SequenceID text,
 	ParentID text,
+	Inode integer,
 	FOREIGN KEY(ParentID) REFERENCES folders(ID));
 	delete from folders;`
 
 	_, err = db.Exec(sqlStmt)
 	if err != nil {
 		log.Print("Failed creating the folders table")
-		return nil, err
+		return err
 	}
 
 	sqlStmt = `create table files
@@ -101,34 +162,70 @@ func NewCache(client box.Client) (SyncCache, error) {
 	SHA1 text,
 	Valid boolean,
 	SequenceID text,
+	ETag text,
 	ParentID text,
+	Inode integer,
+	Locked boolean,
+	LockExpiresAt integer,
 	FOREIGN KEY(ParentID) REFERENCES folders(ID));
 	delete from files;`
 
 	_, err = db.Exec(sqlStmt)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	cache := syncCache{
-		client:              client,
-		db:                  db,
-		localRootDirectory:  defaultLocalRootDirectory,
-		remoteRootDirectory: defaultRemoteRootDirectory,
-		dbLocation:          defaultDBLocation,
+	// Web links are synced as shortcut files, whose paths are kept here
+	// with the link's extension.
+	sqlStmt = `create table weblinks
+	(Path text not null primary key,
+	ID text unique,
+	URL text,
+	SequenceID text,
+	ParentID text,
+	Inode integer,
+	FOREIGN KEY(ParentID) REFERENCES folders(ID));
+	delete from weblinks;`
+
+	_, err = db.Exec(sqlStmt)
+	if err != nil {
+		return err
 	}
 
-	err = cache.HardRefresh()
+	// The files made read-only because of a lock are kept across restarts,
+	// so that they are made writable again once unlocked.
+	sqlStmt = `create table if not exists read_only_files
+	(ID text not null primary key);`
+
+	_, err = db.Exec(sqlStmt)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	err = cache.UpdateCache()
+	// Uploads Box would not accept are tried again after a restart, e.g.
+	// once space was freed.
+	sqlStmt = `create table if not exists skipped_uploads
+	(Path text not null primary key,
+	Size integer,
+	ModTime integer);
+	delete from skipped_uploads;`
+
+	_, err = db.Exec(sqlStmt)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return &cache, nil
+	// Unlike files and folders, pending uploads are kept across restarts so
+	// that they can be resumed.
+	return createUploadTables(db)
+}
+
+// withContext returns a copy of the cache whose requests are made with ctx.
+func (c *syncCache) withContext(ctx context.Context) *syncCache {
+	c2 := *c
+	c2.client = c.client.WithContext(ctx)
+	c2.ctx = ctx
+	return &c2
 }
 
 func (c *syncCache) SetEntryInvalid(path string) error {
@@ -164,94 +261,64 @@ func (c *syncCache) SetEntryInvalid(path string) error {
 	return nil
 }
 
-func (c *syncCache) RescanLocalTree() error {
-	deletesFolder := map[string]string{}
-	row, err := c.db.Query("SELECT Path, ID FROM folders;")
-	if err != nil {
-		return err
-	}
-
-	var pathName string
-	var ID string
-	for row.Next() {
-		row.Scan(&pathName, &ID)
-		deletesFolder[pathName] = ID
-	}
-	row.Close()
-
-	deletesFile := map[string]string{}
-	row, err = c.db.Query("SELECT Path, ID FROM files;")
-	if err != nil {
-		return err
-	}
-
-	for row.Next() {
-		row.Scan(&pathName, &ID)
-		deletesFile[pathName] = ID
-	}
-	row.Close()
+func (c *syncCache) RescanLocalTree(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.withContext(ctx).rescanLocalTree()
+}
 
-	filepath.Walk(c.localRootDirectory, func(filePath string, info os.FileInfo, err error) error {
+// rescanLocalTree brings the remote tree in line with the local one. Items
+// that disappeared from one path and appeared at another are moved remotely
+// rather than deleted and uploaded again, so that they keep their history.
+func (c *syncCache) rescanLocalTree() error {
+	var folders, files []localItem
+	err := filepath.Walk(c.localRootDirectory, func(filePath string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
-		origFile := filePath
-		relPath, err := filepath.Rel(c.localRootDirectory, filePath)
-		if err != nil {
-			relPath = filePath
+		// Stop scanning, without deleting anything, once the scan is
+		// cancelled.
+		if err := c.ctx.Err(); err != nil {
+			return err
 		}
 
-		filePath = filepath.Join(filepath.Base(c.remoteRootDirectory), relPath)
+		if box.IsPartialDownload(filePath) {
+			return nil
+		}
 
+		item := localItem{
+			localPath:  filePath,
+			remotePath: c.remotePath(filePath),
+			inode:      inode(info),
+		}
 		if info.IsDir() {
-			_, ok := deletesFolder[filePath]
-			if ok {
-				delete(deletesFolder, filePath)
-			} else {
-				c.addFolderToDB(filePath)
-			}
+			folders = append(folders, item)
 		} else {
-			_, ok := deletesFile[filePath]
-			if ok {
-				delete(deletesFile, filePath)
-				row, err := c.db.Query(`select ID, SHA1, SequenceID FROM files where Path = "` + filePath + `";`)
-				if err != nil {
-					return err
-				}
-
-				var ID string
-				var SHA1 string
-				var SequenceID string
-				if row.Next() {
-					row.Scan(&ID, &SHA1, &SequenceID)
-					row.Close()
-				} else {
-					return errors.New("Did not find a file where we expected one")
-				}
-
-				if SHA1 != sync.SHA1(origFile) {
-					file, err := c.client.GetFile(ID)
-					if err != nil {
-						return err
-					}
-
-					if file.SequenceID == SequenceID {
-						_, err := c.client.UploadFileVersion(ID, origFile)
-						if err != nil {
-							return err
-						}
-					} else {
-						return errors.New("Conflict Resolution not yet Implemented.")
-					}
-				}
-			} else {
-				c.AddFileToDB(origFile)
-			}
+			files = append(files, item)
 		}
-
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	// Folders are synced first so that files in a moved folder are found at
+	// their new paths.
+	deletesFolder, err := c.syncLocalFolders(folders)
+	if err != nil {
+		return err
+	}
+
+	files, deletesWebLink, err := c.syncLocalWebLinks(files)
+	if err != nil {
+		return err
+	}
+
+	deletesFile, err := c.syncLocalFiles(files)
+	if err != nil {
+		return err
+	}
 
 	tx, err := c.db.Begin()
 	if err != nil {
@@ -259,35 +326,138 @@ func (c *syncCache) RescanLocalTree() error {
 	}
 
 	deleteFolderStmt, err := tx.Prepare(`delete from folders where Path = ?;`)
+	if err != nil {
+		return err
+	}
 	deleteFileStmt, err := tx.Prepare(`delete from files where Path = ?;`)
 	if err != nil {
 		return err
 	}
+	deleteWebLinkStmt, err := tx.Prepare(`delete from weblinks where Path = ?;`)
+	if err != nil {
+		return err
+	}
 
+	// Items that are already gone remotely, e.g. files inside a folder that
+	// was deleted recursively, only need to be removed from the cache.
 	for k, v := range deletesFolder {
 		deleteFolderStmt.Exec(k)
-		err := c.client.DeleteFolder(v, true)
-		if err != nil {
-			return err
+		err := c.client.DeleteFolder(v.ID, true)
+		if err != nil && !box.IsNotFound(err) {
+			log.Printf("Failed to delete folder %s: %v", k, err)
 		}
 	}
 
+	// Files that are locked, that the user may not delete or that were
+	// changed remotely since they were last synced are kept, and their local
+	// copies restored once the deletes are recorded.
+	restores := map[string]string{}
 	for k, v := range deletesFile {
+		etag, err := c.syncedETag(v.ID, v.SHA1, v.ETag)
+		if err == nil {
+			err = c.client.IfMatch(etag).DeleteFile(v.ID)
+		}
+		if box.IsForbidden(err) || box.IsPreconditionFailed(err) {
+			log.Printf("Not deleting file %s, restoring it: %v", k, err)
+			restores[k] = v.ID
+			continue
+		}
 		deleteFileStmt.Exec(k)
-		err := c.client.DeleteFile(v)
-		if err != nil {
-			return err
+		if err != nil && !box.IsNotFound(err) {
+			log.Printf("Failed to delete file %s: %v", k, err)
 		}
 	}
 
+	for k, v := range deletesWebLink {
+		deleteWebLinkStmt.Exec(k)
+		err := c.client.DeleteWebLink(v.ID)
+		if err != nil && !box.IsNotFound(err) {
+			log.Printf("Failed to delete web link %s: %v", k, err)
+		}
+	}
+
+	deleteWebLinkStmt.Close()
 	deleteFileStmt.Close()
 	deleteFolderStmt.Close()
 	tx.Commit()
 
+	for k, id := range restores {
+		err := c.restoreFile(k, id)
+		if err != nil {
+			log.Printf("Failed to restore file %s: %v", k, err)
+		}
+	}
+
 	return nil
 }
 
-func (c *syncCache) HardRefresh() error {
+// syncLocalFile uploads the local file at localPath if it changed since it was
+// last synced with remotePath.
+func (c *syncCache) syncLocalFile(localPath, remotePath string) error {
+	var ID, SHA1, ETag, ParentID string
+	var Locked bool
+	var LockExpiresAt sql.NullInt64
+	err := c.db.QueryRow(`select ID, SHA1, coalesce(ETag, ''), ParentID, coalesce(Locked, 0), LockExpiresAt FROM files where Path = ?;`, remotePath).
+		Scan(&ID, &SHA1, &ETag, &ParentID, &Locked, &LockExpiresAt)
+	if err == sql.ErrNoRows {
+		return errors.New("Did not find a file where we expected one")
+	} else if err != nil {
+		return err
+	}
+
+	if SHA1 == sync.SHA1(localPath) {
+		return nil
+	}
+
+	if Locked {
+		if cachedLock(LockExpiresAt).Active(time.Now()) {
+			// The changes are uploaded by the first scan after the
+			// file is unlocked, as a conflicted copy if it was edited
+			// remotely.
+			log.Printf("%s is locked by another user, not uploading local changes", localPath)
+			return nil
+		}
+
+		// Box sends no event when a lock expires.
+		err = c.applyRemoteLock(ID, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Box only accepts the new version if the remote file is still the one
+	// last synced, so a version uploaded elsewhere in the meantime is never
+	// overwritten.
+	ETag, err = c.syncedETag(ID, SHA1, ETag)
+	var file *box.File
+	if err == nil {
+		file, err = c.uploadFile(localPath, "", ID, ETag)
+	}
+	if box.IsNotFound(err) {
+		// The remote file was deleted while the local copy was being
+		// edited, so upload the edited copy again.
+		_, err = c.db.Exec(`delete from files where Path = ?;`, remotePath)
+		if err != nil {
+			return err
+		}
+		_, err = c.addFileToDB(remotePath, localPath)
+		return err
+	} else if box.IsPreconditionFailed(err) {
+		return c.resolveConflict(localPath, remotePath, ID, ParentID)
+	} else if err != nil || file == nil {
+		return err
+	}
+
+	return c.updateUploadedFile(localPath, file)
+}
+
+func (c *syncCache) HardRefresh(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.withContext(ctx).hardRefresh()
+}
+
+func (c *syncCache) hardRefresh() error {
 	rootFolder, err := sync.GetSyncRootFolder(c.client)
 	if err != nil {
 		return err
@@ -312,80 +482,121 @@ func (c *syncCache) HardRefresh() error {
 	return nil
 }
 
-func (c *syncCache) UpdateCache() error {
+func (c *syncCache) UpdateCache(ctx context.Context) error {
 	return nil
 }
 
 func (c *syncCache) hardCacheAll(folderID, destPath, remotePath string) error {
-	tx, err := c.db.Begin()
-	if err != nil {
+	if err := c.ctx.Err(); err != nil {
 		return err
 	}
 
-	updateFileStmt, err := tx.Prepare(`update files set ID = ?, Valid = ?, SHA1 = ?, SequenceID = ?, ParentID = ? where Path = ?;`)
+	tx, err := c.db.Begin()
 	if err != nil {
 		return err
 	}
 
-	insertFileStmt, err := tx.Prepare(`insert into files (Path, ID, SHA1, Valid, SequenceID, ParentID) values (?, ?, ?, ?, ?, ?);`)
+	updateFileStmt, err := tx.Prepare(`update files set ID = ?, Valid = ?, SHA1 = ?, SequenceID = ?, ETag = ?, ParentID = ?, Inode = ?, Locked = ?, LockExpiresAt = ? where Path = ?;`)
 	if err != nil {
 		return err
 	}
 
-	contents, err := c.client.GetFolderContents(folderID)
+	insertFileStmt, err := tx.Prepare(`insert into files (Path, ID, SHA1, Valid, SequenceID, ETag, ParentID, Inode, Locked, LockExpiresAt) values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`)
 	if err != nil {
 		return err
 	}
 
-	for _, file := range contents.Files {
-		var filePathLoc string
-		var fileSHA string
-		remoteFilePath := path.Join(remotePath, file.Name)
-		remoteRelPath, err := filepath.Rel(c.remoteRootDirectory, remoteFilePath)
-		if err != nil {
-			return err
-		}
-
-		localFilePath := path.Join(destPath, remoteRelPath)
-
-		rows, err := c.db.Query(`SELECT Path, SHA1 FROM files WHERE Path = "` + remoteFilePath + `";`)
-		if err != nil {
-			return err
-		}
+	// Files are cached page by page; folders are recursed into once the
+	// whole listing has been read, and lock modes applied once the files
+	// are recorded.
+	var folders []box.Folder
+	type lockMode struct {
+		localPath string
+		locked    bool
+	}
+	lockModes := map[string]lockMode{}
+	var webLinks []box.WebLink
+	it := c.client.GetFolderItems(folderID, nil)
+	for it.Next() {
+		contents := it.Page()
+		folders = append(folders, contents.Folders...)
+		webLinks = append(webLinks, contents.WebLinks...)
+
+		for _, file := range contents.Files {
+			var filePathLoc string
+			var fileSHA string
+			remoteFilePath := path.Join(remotePath, file.Name)
+			remoteRelPath, err := filepath.Rel(c.remoteRootDirectory, remoteFilePath)
+			if err != nil {
+				return err
+			}
 
-		if rows.Next() {
-			rows.Scan(&filePathLoc, &fileSHA)
-			rows.Close()
+			localFilePath := path.Join(destPath, remoteRelPath)
+			locked := c.lockedByOther(file.Lock)
 
-			_, err = updateFileStmt.Exec(file.ID, true, file.SHA1, file.SequenceID, folderID, remoteFilePath)
+			rows, err := c.db.Query(`SELECT Path, SHA1 FROM files WHERE Path = "` + remoteFilePath + `";`)
 			if err != nil {
 				return err
 			}
 
-			if strings.Compare(fileSHA, file.SHA1) != 0 {
-				err = c.client.DownloadFile(file.ID, localFilePath)
+			if rows.Next() {
+				rows.Scan(&filePathLoc, &fileSHA)
+				rows.Close()
+
+				if strings.Compare(fileSHA, file.SHA1) != 0 && !c.hasPendingUpload(localFilePath) {
+					err = c.client.DownloadFile(file.ID, localFilePath)
+					if err != nil {
+						log.Print("Failed to download file")
+						return err
+					}
+				}
+
+				_, err = updateFileStmt.Exec(file.ID, true, file.SHA1, file.SequenceID, file.ETag, folderID, localInode(localFilePath), locked, lockExpiry(file.Lock), remoteFilePath)
 				if err != nil {
-					log.Print("Failed to download file")
 					return err
 				}
-			}
-		} else {
-			rows.Close()
+			} else {
+				rows.Close()
 
-			_, err = insertFileStmt.Exec(remoteFilePath, file.ID, file.SHA1, true, file.SequenceID, folderID)
-			err = c.client.DownloadFile(file.ID, localFilePath)
-			if err != nil {
-				log.Print("Failed to download file")
-				return err
+				// Keep the local changes that are still being uploaded.
+				if !c.hasPendingUpload(localFilePath) {
+					err = c.client.DownloadFile(file.ID, localFilePath)
+					if err != nil {
+						log.Print("Failed to download file")
+						return err
+					}
+				}
+
+				_, err = insertFileStmt.Exec(remoteFilePath, file.ID, file.SHA1, true, file.SequenceID, file.ETag, folderID, localInode(localFilePath), locked, lockExpiry(file.Lock))
 			}
+
+			// Files locked by other users are read-only locally.
+			lockModes[file.ID] = lockMode{localFilePath, locked}
 		}
 	}
+	if err := it.Err(); err != nil {
+		return err
+	}
 
 	insertFileStmt.Close()
 	updateFileStmt.Close()
 	tx.Commit()
 
-	for _, folder := range contents.Folders {
+	for id, mode := range lockModes {
+		err := c.applyLockMode(id, mode.localPath, mode.locked)
+		if err != nil {
+			log.Printf("Failed to change the permissions of %s: %v", mode.localPath, err)
+		}
+	}
+
+	for _, webLink := range webLinks {
+		err := c.cacheWebLink(webLink, folderID, path.Join(remotePath, webLink.Name+shortcutExt))
+		if err != nil {
+			log.Printf("Failed to sync web link %s: %v", webLink.Name, err)
+		}
+	}
+
+	for _, folder := range folders {
 
 		//Build the local and remote paths
 		var folderPathLoc string
@@ -397,6 +608,15 @@ func (c *syncCache) hardCacheAll(folderID, destPath, remotePath string) error {
 
 		localFolderPath := path.Join(destPath, remoteRelPath)
 
+		if _, err := os.Stat(localFolderPath); os.IsNotExist(err) {
+			fmt.Printf("Creating directory %s\n", localFolderPath)
+			err := os.MkdirAll(localFolderPath, 0755)
+			if err != nil {
+				log.Print("Creating directory error.")
+				return err
+			}
+		}
+
 		//Grab the desired DB entry
 		rows, err := c.db.Query(`SELECT Path FROM folders WHERE Path = "` + remoteFolderPath + `";`)
 		if err != nil {
@@ -404,7 +624,7 @@ func (c *syncCache) hardCacheAll(folderID, destPath, remotePath string) error {
 		}
 
 		if rows.Next() {
-			updateFolderStmt, err := c.db.Prepare(`update folders set ID = ?, Valid = ?, SequenceID = ?, ParentID = ? where Path = ?;`)
+			updateFolderStmt, err := c.db.Prepare(`update folders set ID = ?, Valid = ?, SequenceID = ?, ParentID = ?, Inode = ? where Path = ?;`)
 			if err != nil {
 				return err
 			}
@@ -412,7 +632,7 @@ func (c *syncCache) hardCacheAll(folderID, destPath, remotePath string) error {
 			rows.Scan(&folderPathLoc)
 			rows.Close()
 
-			_, err = updateFolderStmt.Exec(folder.ID, true, folder.SequenceID, folderID, remoteFolderPath)
+			_, err = updateFolderStmt.Exec(folder.ID, true, folder.SequenceID, folderID, localInode(localFolderPath), remoteFolderPath)
 			if err != nil {
 				return err
 			}
@@ -421,12 +641,12 @@ func (c *syncCache) hardCacheAll(folderID, destPath, remotePath string) error {
 		} else {
 			rows.Close()
 
-			insertFolderStmt, err := c.db.Prepare(`insert into folders (Path, ID, Valid, SequenceID, ParentID) values (?, ?, ?, ?, ?)`)
+			insertFolderStmt, err := c.db.Prepare(`insert into folders (Path, ID, Valid, SequenceID, ParentID, Inode) values (?, ?, ?, ?, ?, ?)`)
 			if err != nil {
 				return nil
 			}
 
-			_, err = insertFolderStmt.Exec(remoteFolderPath, folder.ID, true, folder.SequenceID, folderID)
+			_, err = insertFolderStmt.Exec(remoteFolderPath, folder.ID, true, folder.SequenceID, folderID, localInode(localFolderPath))
 			if err != nil {
 				return err
 			}
@@ -434,15 +654,6 @@ func (c *syncCache) hardCacheAll(folderID, destPath, remotePath string) error {
 			insertFolderStmt.Close()
 		}
 
-		if _, err := os.Stat(localFolderPath); os.IsNotExist(err) {
-			fmt.Printf("Creating directory %s\n", localFolderPath)
-			err := os.MkdirAll(localFolderPath, 0755)
-			if err != nil {
-				log.Print("Creating directory error.")
-				return err
-			}
-		}
-
 		err = c.hardCacheAll(folder.ID, destPath, remoteFolderPath)
 		if err != nil {
 			return err
@@ -480,16 +691,25 @@ func (c *syncCache) addFolderToDB(folderPath string) (string, error) {
 		}
 
 		folder, err := c.client.CreateFolder(filepath.Base(folderPath), parentID)
+		if box.IsItemNameInUse(err) {
+			// The folder already exists remotely, so use it.
+			conflicts := err.(*box.APIError).Conflicts()
+			if len(conflicts) > 0 && conflicts[0].Type == box.TypeFolder {
+				folder = &box.Folder{ID: conflicts[0].ID, SequenceID: conflicts[0].SequenceID}
+				err = nil
+			}
+		}
 		if err != nil {
 			return "", err
 		}
+		ID = folder.ID
 
-		stmt, err := c.db.Prepare("INSERT OR IGNORE into folders (Path, ID, Valid, SequenceID, ParentID) values (?, ?, ?, ?, ?)")
+		stmt, err := c.db.Prepare("INSERT OR IGNORE into folders (Path, ID, Valid, SequenceID, ParentID, Inode) values (?, ?, ?, ?, ?, ?)")
 		if err != nil {
 			return "", err
 		}
 
-		_, err = stmt.Exec(folderPath, folder.ID, true, folder.SequenceID, parentID)
+		_, err = stmt.Exec(folderPath, folder.ID, true, folder.SequenceID, parentID, localInode(c.localPath(folderPath)))
 		if err != nil {
 			return "", err
 		}
@@ -528,17 +748,25 @@ func (c *syncCache) addFileToDB(filePath, origFile string) (string, error) {
 			return "", err
 		}
 
-		file, err := c.client.UploadFile(origFile, parentID)
+		file, err := c.uploadFile(origFile, parentID, "", "")
+		if box.IsItemNameInUse(err) {
+			return parentID, c.resolveUploadConflict(origFile, filePath, parentID, err.(*box.APIError))
+		}
 		if err != nil {
 			return "", err
 		}
+		if file == nil {
+			// The file is not cached, so that it is uploaded once it
+			// changes.
+			return parentID, nil
+		}
 
-		stmt, err := c.db.Prepare("INSERT OR IGNORE into files (Path, ID, Valid, SequenceID, SHA1, ParentID) values (?, ?, ?, ?, ?, ?)")
+		stmt, err := c.db.Prepare("INSERT OR IGNORE into files (Path, ID, Valid, SequenceID, ETag, SHA1, ParentID, Inode) values (?, ?, ?, ?, ?, ?, ?, ?)")
 		if err != nil {
 			return "", err
 		}
 
-		_, err = stmt.Exec(filePath, file.ID, true, file.SequenceID, file.SHA1, parentID)
+		_, err = stmt.Exec(filePath, file.ID, true, file.SequenceID, file.ETag, file.SHA1, parentID, localInode(origFile))
 		stmt.Close()
 		if err != nil {
 			return "", err
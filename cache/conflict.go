@@ -0,0 +1,113 @@
+package cache
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gitlab.engr.illinois.edu/sp-box/boxsync/box"
+	"gitlab.engr.illinois.edu/sp-box/boxsync/sync"
+)
+
+// resolveUploadConflict handles an upload of the local file at localPath that
+// failed because an item with the same name already exists remotely.
+func (c *syncCache) resolveUploadConflict(localPath, remotePath, parentID string, apiErr *box.APIError) error {
+	conflicts := apiErr.Conflicts()
+	if len(conflicts) == 0 || conflicts[0].Type != box.TypeFile {
+		return apiErr
+	}
+	return c.resolveConflict(localPath, remotePath, conflicts[0].ID, parentID)
+}
+
+// resolveConflict reconciles the local file at localPath with the remote file
+// fileID when both have changed independently. If their contents are the same
+// the remote file is simply recorded. Otherwise the local changes are moved to
+// a conflicted copy, which is uploaded as a new file, and the remote version is
+// downloaded in place of the local one.
+func (c *syncCache) resolveConflict(localPath, remotePath, fileID, parentID string) error {
+	remote, err := c.client.GetFile(fileID)
+	if err != nil {
+		return err
+	}
+
+	if remote.SHA1 == sync.SHA1(localPath) {
+		return c.recordFile(remotePath, remote, parentID)
+	}
+
+	conflictPath := conflictedCopyPath(localPath, time.Now())
+	log.Printf("%s conflicts with the remote version, keeping local changes in %s", localPath, conflictPath)
+
+	err = os.Rename(localPath, conflictPath)
+	if err != nil {
+		return err
+	}
+
+	err = c.client.DownloadFile(fileID, localPath)
+	if err != nil {
+		// Put the local file back so it is not mistaken for a deletion.
+		os.Rename(conflictPath, localPath)
+		return err
+	}
+
+	err = c.recordFile(remotePath, remote, parentID)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.AddFileToDB(conflictPath)
+	return err
+}
+
+// syncedETag returns etag, the cached ETag of the file fileID as last synced
+// with the content sha1, fetching it if it was not cached. Since the remote
+// file can then only be checked by content, an error that satisfies
+// box.IsPreconditionFailed is returned, as Box would, if it has changed.
+func (c *syncCache) syncedETag(fileID, sha1, etag string) (string, error) {
+	if etag != "" {
+		return etag, nil
+	}
+	remote, err := c.client.GetFile(fileID)
+	if err != nil {
+		return "", err
+	}
+	if remote.SHA1 != sha1 {
+		return "", &box.APIError{StatusCode: http.StatusPreconditionFailed, Code: box.ErrorCodePreconditionFailed}
+	}
+	return remote.ETag, nil
+}
+
+// restoreFile downloads the remote file fileID to the deleted local copy of
+// remotePath, when the remote file cannot or should not be deleted.
+func (c *syncCache) restoreFile(remotePath, fileID string) error {
+	remote, err := c.client.GetFile(fileID)
+	if err != nil {
+		return err
+	}
+	localPath := c.localPath(remotePath)
+	err = c.client.DownloadFile(fileID, localPath)
+	if err != nil {
+		return err
+	}
+	_, err = c.db.Exec(`update files set SHA1 = ?, SequenceID = ?, ETag = ?, Inode = ? where Path = ?;`,
+		remote.SHA1, remote.SequenceID, remote.ETag, localInode(localPath), remotePath)
+	return err
+}
+
+// recordFile stores file in the cache as the synced version of remotePath.
+func (c *syncCache) recordFile(remotePath string, file *box.File, parentID string) error {
+	_, err := c.db.Exec(`insert or replace into files (Path, ID, SHA1, Valid, SequenceID, ETag, ParentID, Inode) values (?, ?, ?, ?, ?, ?, ?, ?);`,
+		remotePath, file.ID, file.SHA1, true, file.SequenceID, file.ETag, parentID, localInode(c.localPath(remotePath)))
+	return err
+}
+
+// conflictedCopyPath returns the path used to keep the local version of
+// localPath when it conflicts with the remote version.
+func conflictedCopyPath(localPath string, t time.Time) string {
+	ext := filepath.Ext(localPath)
+	base := strings.TrimSuffix(localPath, ext)
+	return fmt.Sprintf("%s (conflicted copy %s)%s", base, t.Format("2006-01-02 150405"), ext)
+}
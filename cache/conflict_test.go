@@ -0,0 +1,136 @@
+package cache
+
+import (
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gitlab.engr.illinois.edu/sp-box/boxsync/box"
+)
+
+func TestRescanResolvesConflicts(t *testing.T) {
+	var uploaded, versions []string
+	client := &fakeClient{
+		getFile: func(id string) (*box.File, error) {
+			return &box.File{ID: id, SHA1: "remote", ETag: "2"}, nil
+		},
+		downloadFile: func(id, destPath string) error {
+			return ioutil.WriteFile(destPath, []byte("remote "+id), 0644)
+		},
+		preflightUpload: func(name, parentID string) error {
+			if name == "new.txt" {
+				return &box.APIError{
+					StatusCode:  http.StatusConflict,
+					Code:        box.ErrorCodeItemNameInUse,
+					ContextInfo: []byte(`{"conflicts": {"type": "file", "id": "9"}}`),
+				}
+			}
+			return nil
+		},
+		preflightUploadVersion: func(fileID string) error {
+			if fileID == "6" {
+				return &box.APIError{StatusCode: http.StatusNotFound, Code: box.ErrorCodeNotFound}
+			}
+			return nil
+		},
+		uploadFile: func(srcPath, parentID string) (*box.File, error) {
+			uploaded = append(uploaded, filepath.Base(srcPath))
+			return &box.File{ID: strconv.Itoa(20 + len(uploaded)), SHA1: "uploaded"}, nil
+		},
+		uploadFileVersion: func(fileID, srcPath, ifMatch string) (*box.File, error) {
+			versions = append(versions, fileID+"@"+ifMatch)
+			return nil, &box.APIError{StatusCode: http.StatusPreconditionFailed, Code: box.ErrorCodePreconditionFailed}
+		},
+	}
+	c, cleanup := newTestCache(t, client)
+	defer cleanup()
+
+	editedPath := writeLocalFile(t, c, "edited.txt", "local edit")
+	writeLocalFile(t, c, "gone.txt", "edited after the remote delete")
+	newPath := writeLocalFile(t, c, "new.txt", "local new")
+	writeLocalFile(t, c, "plain.txt", "plain")
+	_, err := c.db.Exec(`insert into files (Path, ID, SHA1, ETag, ParentID) values
+		('Box Sync/edited.txt', '5', 'old', '1', '0'),
+		('Box Sync/gone.txt', '6', 'old', '1', '0');`)
+	assert.NoError(t, err, "Function should not return error")
+
+	err = c.rescanLocalTree()
+	assert.NoError(t, err, "Function should not return error")
+
+	assert.Equal(t, []string{"5@1"}, versions, "Edited file should be uploaded only if unchanged remotely")
+	assert.Len(t, uploaded, 4, "Every new file and conflicted copy should be uploaded")
+	assert.Equal(t, "gone.txt", uploaded[1], "File deleted remotely should be uploaded again")
+	assert.Equal(t, "plain.txt", uploaded[3], "Scan should continue after the conflicts")
+
+	for localPath, id := range map[string]string{editedPath: "5", newPath: "9"} {
+		content, err := ioutil.ReadFile(localPath)
+		assert.NoError(t, err, "Function should not return error")
+		assert.Equal(t, "remote "+id, string(content), "Remote version should replace the local one")
+	}
+
+	copies, err := filepath.Glob(filepath.Join(c.localRootDirectory, "* (conflicted copy *)*"))
+	assert.NoError(t, err, "Function should not return error")
+	assert.Len(t, copies, 2, "Local changes should be kept in conflicted copies")
+	sort.Strings(copies)
+	for i, content := range []string{"local edit", "local new"} {
+		data, err := ioutil.ReadFile(copies[i])
+		assert.NoError(t, err, "Function should not return error")
+		assert.Equal(t, content, string(data), "Conflicted copy should hold the local changes")
+		assert.Contains(t, uploaded, filepath.Base(copies[i]), "Conflicted copy should be uploaded")
+	}
+
+	var id string
+	err = c.db.QueryRow(`select ID from files where Path = 'Box Sync/new.txt';`).Scan(&id)
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, "9", id, "Conflicting remote file should be cached in place of the new one")
+	err = c.db.QueryRow(`select ID from files where Path = 'Box Sync/gone.txt';`).Scan(&id)
+	assert.NoError(t, err, "Function should not return error")
+	assert.NotEqual(t, "6", id, "File uploaded again should be cached under its new ID")
+}
+
+func TestSyncLocalFileFetchesMissingETag(t *testing.T) {
+	var versions []string
+	remoteSHA1 := map[string]string{"5": "old", "6": "changed"}
+	client := &fakeClient{
+		getFile: func(id string) (*box.File, error) {
+			return &box.File{ID: id, SHA1: remoteSHA1[id], ETag: "3"}, nil
+		},
+		downloadFile: func(id, destPath string) error {
+			return ioutil.WriteFile(destPath, []byte("remote "+id), 0644)
+		},
+		uploadFile: func(srcPath, parentID string) (*box.File, error) {
+			return &box.File{ID: "9", SHA1: "copy"}, nil
+		},
+		uploadFileVersion: func(fileID, srcPath, ifMatch string) (*box.File, error) {
+			versions = append(versions, fileID+"@"+ifMatch)
+			return &box.File{ID: fileID, SHA1: "new", ETag: "4"}, nil
+		},
+	}
+	c, cleanup := newTestCache(t, client)
+	defer cleanup()
+
+	unchangedPath := writeLocalFile(t, c, "unchanged.txt", "local edit")
+	changedPath := writeLocalFile(t, c, "changed.txt", "local edit")
+	_, err := c.db.Exec(`insert into files (Path, ID, SHA1, ParentID) values
+		('Box Sync/unchanged.txt', '5', 'old', '0'),
+		('Box Sync/changed.txt', '6', 'old', '0');`)
+	assert.NoError(t, err, "Function should not return error")
+
+	err = c.syncLocalFile(unchangedPath, "Box Sync/unchanged.txt")
+	assert.NoError(t, err, "Function should not return error")
+	err = c.syncLocalFile(changedPath, "Box Sync/changed.txt")
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, []string{"5@3"}, versions, "Upload without a cached ETag should be conditional on the fetched one")
+
+	content, err := ioutil.ReadFile(changedPath)
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, "remote 6", string(content), "File changed remotely should be replaced by the remote version")
+	copies, err := filepath.Glob(filepath.Join(c.localRootDirectory, "changed (conflicted copy *).txt"))
+	assert.NoError(t, err, "Function should not return error")
+	assert.Len(t, copies, 1, "Local changes should be kept in a conflicted copy")
+}
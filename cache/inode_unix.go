@@ -0,0 +1,18 @@
+//go:build !windows
+// +build !windows
+
+package cache
+
+import (
+	"os"
+	"syscall"
+)
+
+// inode returns the inode number of the file described by info, or 0 if it is
+// not known.
+func inode(info os.FileInfo) int64 {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return int64(stat.Ino)
+	}
+	return 0
+}
@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"database/sql"
+	"os"
+	"time"
+
+	"gitlab.engr.illinois.edu/sp-box/boxsync/box"
+)
+
+// lockedByOther reports whether lock, which may be nil, stops the syncing user
+// from uploading changes to a file because another user holds it.
+func (c *syncCache) lockedByOther(lock *box.Lock) bool {
+	return lock.Active(time.Now()) && lock.CreatedBy.ID != c.userID
+}
+
+// applyRemoteLock records lock, the lock another user holds on the file id or
+// nil if there is none, and makes its local copy read-only while the lock is
+// active, so that it is not edited by mistake.
+func (c *syncCache) applyRemoteLock(id string, lock *box.Lock) error {
+	remotePath, err := c.cachedPath("files", id)
+	if err == sql.ErrNoRows {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	locked := lock.Active(time.Now())
+	_, err = c.db.Exec(`update files set Locked = ?, LockExpiresAt = ? where Path = ?;`, locked, lockExpiry(lock), remotePath)
+	if err != nil {
+		return err
+	}
+	return c.applyLockMode(id, c.localPath(remotePath), locked)
+}
+
+// applyLockMode makes the local copy at localPath of the file id read-only
+// while the file is locked by another user. Write permission is only given
+// back to files this client made read-only, which are recorded across
+// restarts, so that a read-only mode set by the user is kept.
+func (c *syncCache) applyLockMode(id, localPath string, locked bool) error {
+	if locked {
+		_, err := c.db.Exec(`insert or ignore into read_only_files (ID) values (?);`, id)
+		if err != nil {
+			return err
+		}
+		return setReadOnly(localPath, true)
+	}
+
+	res, err := c.db.Exec(`delete from read_only_files where ID = ?;`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return err
+	}
+	return setReadOnly(localPath, false)
+}
+
+// lockExpiry returns when lock expires as cached in the files table, which is
+// NULL for a lock that does not expire.
+func lockExpiry(lock *box.Lock) sql.NullInt64 {
+	if lock == nil || lock.ExpiredAt == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: lock.ExpiredAt.UnixNano(), Valid: true}
+}
+
+// cachedLock returns the lock cached with expiresAt in the files table.
+func cachedLock(expiresAt sql.NullInt64) *box.Lock {
+	lock := &box.Lock{}
+	if expiresAt.Valid {
+		t := time.Unix(0, expiresAt.Int64)
+		lock.ExpiredAt = &t
+	}
+	return lock
+}
+
+// setReadOnly removes all write permissions from the local file at localPath,
+// or gives write permission back to its owner.
+func setReadOnly(localPath string, readOnly bool) error {
+	info, err := os.Stat(localPath)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	perm := info.Mode().Perm()
+	if readOnly {
+		perm &^= 0222
+	} else {
+		perm |= 0200
+	}
+	if perm == info.Mode().Perm() {
+		return nil
+	}
+	return os.Chmod(localPath, perm)
+}
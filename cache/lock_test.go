@@ -0,0 +1,184 @@
+package cache
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"gitlab.engr.illinois.edu/sp-box/boxsync/box"
+)
+
+func TestHandleLockEvents(t *testing.T) {
+	localRoot, err := ioutil.TempDir("", "boxsync")
+	assert.NoError(t, err, "Function should not return error")
+	defer os.RemoveAll(localRoot)
+	localPath := filepath.Join(localRoot, "notes.txt")
+	err = ioutil.WriteFile(localPath, []byte("notes"), 0644)
+	assert.NoError(t, err, "Function should not return error")
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	assert.NoError(t, err, "Function should not return error")
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	_, err = db.Exec(`create table files (Path text not null primary key, ID text unique, SHA1 text, Valid boolean, SequenceID text, ETag text, ParentID text, Inode integer, Locked boolean, LockExpiresAt integer);
+	create table read_only_files (ID text not null primary key);
+	insert into files (Path, ID) values ('Box Sync/notes.txt', '5');`)
+	assert.NoError(t, err, "Function should not return error")
+
+	c := &syncCache{
+		db:                  db,
+		localRootDirectory:  localRoot,
+		remoteRootDirectory: "Box Sync",
+		userID:              "1",
+	}
+	lockEvent := func(eventType, userID string) box.Event {
+		return box.Event{
+			EventType: eventType,
+			CreatedBy: box.User{ID: userID},
+			Source:    []byte(`{"type": "file", "id": "5", "name": "notes.txt"}`),
+		}
+	}
+	expiringLockEvent := func(expiredAt time.Time) box.Event {
+		event := lockEvent(box.EventTypeLockCreate, "9")
+		event.Source = []byte(`{"type": "file", "id": "5", "name": "notes.txt", "lock": {"type": "lock", "id": "7", "expired_at": "` +
+			expiredAt.Format(time.RFC3339) + `"}}`)
+		return event
+	}
+	assertLocked := func(locked bool, perm os.FileMode, msg string) {
+		var cached bool
+		err := db.QueryRow(`select Locked from files where ID = '5';`).Scan(&cached)
+		assert.NoError(t, err, "Function should not return error")
+		assert.Equal(t, locked, cached, msg)
+		info, err := os.Stat(localPath)
+		assert.NoError(t, err, "Function should not return error")
+		assert.Equal(t, perm, info.Mode().Perm(), msg)
+	}
+
+	err = c.handleEvent(lockEvent(box.EventTypeLockCreate, "9"))
+	assert.NoError(t, err, "Function should not return error")
+	assertLocked(true, 0444, "File locked by another user should be read-only")
+
+	err = c.handleEvent(lockEvent(box.EventTypeLockDestroy, "9"))
+	assert.NoError(t, err, "Function should not return error")
+	assertLocked(false, 0644, "Unlocked file should be writable again")
+
+	err = c.handleEvent(lockEvent(box.EventTypeLockCreate, "1"))
+	assert.NoError(t, err, "Function should not return error")
+	assertLocked(false, 0644, "File locked by the syncing user should stay writable")
+
+	err = c.handleEvent(expiringLockEvent(time.Now().Add(-time.Hour)))
+	assert.NoError(t, err, "Function should not return error")
+	assertLocked(false, 0644, "File whose lock already expired should stay writable")
+
+	expiresAt := time.Now().Add(time.Hour)
+	err = c.handleEvent(expiringLockEvent(expiresAt))
+	assert.NoError(t, err, "Function should not return error")
+	assertLocked(true, 0444, "File with an expiring lock should be read-only")
+	var cachedExpiry int64
+	err = db.QueryRow(`select LockExpiresAt from files where ID = '5';`).Scan(&cachedExpiry)
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, expiresAt.Truncate(time.Second).UnixNano(), cachedExpiry, "Lock expiry should be cached")
+
+	err = c.handleEvent(lockEvent(box.EventTypeLockDestroy, "9"))
+	assert.NoError(t, err, "Function should not return error")
+	assertLocked(false, 0644, "Unlocked file should be writable again")
+
+	err = os.Chmod(localPath, 0444)
+	assert.NoError(t, err, "Function should not return error")
+	err = c.handleEvent(lockEvent(box.EventTypeLockDestroy, "9"))
+	assert.NoError(t, err, "Function should not return error")
+	assertLocked(false, 0444, "File made read-only by the user should stay read-only")
+}
+
+func TestExpiredLockAllowsUpload(t *testing.T) {
+	var versions []string
+	client := &fakeClient{
+		uploadFileVersion: func(fileID, srcPath, ifMatch string) (*box.File, error) {
+			versions = append(versions, fileID)
+			return &box.File{ID: fileID, SHA1: "new", ETag: "2"}, nil
+		},
+	}
+	c, cleanup := newTestCache(t, client)
+	defer cleanup()
+
+	lockedPath := writeLocalFile(t, c, "locked.txt", "local edit")
+	expiredPath := writeLocalFile(t, c, "expired.txt", "local edit")
+	err := os.Chmod(expiredPath, 0444)
+	assert.NoError(t, err, "Function should not return error")
+	_, err = c.db.Exec(`insert into files (Path, ID, SHA1, ETag, ParentID, Locked, LockExpiresAt) values
+		('Box Sync/locked.txt', '5', 'old', '1', '0', 1, ?),
+		('Box Sync/expired.txt', '6', 'old', '1', '0', 1, ?);`,
+		time.Now().Add(time.Hour).UnixNano(), time.Now().Add(-time.Hour).UnixNano())
+	assert.NoError(t, err, "Function should not return error")
+	_, err = c.db.Exec(`insert into read_only_files (ID) values ('6');`)
+	assert.NoError(t, err, "Function should not return error")
+
+	err = c.syncLocalFile(lockedPath, "Box Sync/locked.txt")
+	assert.NoError(t, err, "Function should not return error")
+	err = c.syncLocalFile(expiredPath, "Box Sync/expired.txt")
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, []string{"6"}, versions, "Only the file whose lock expired should be uploaded")
+
+	var locked bool
+	err = c.db.QueryRow(`select Locked from files where ID = '6';`).Scan(&locked)
+	assert.NoError(t, err, "Function should not return error")
+	assert.False(t, locked, "Expired lock should be forgotten")
+	info, err := os.Stat(expiredPath)
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, os.FileMode(0644), info.Mode().Perm(), "File whose lock expired should be writable again")
+}
+
+func TestRescanRestoresFileItCannotDelete(t *testing.T) {
+	var deleted []string
+	client := &fakeClient{
+		getFile: func(id string) (*box.File, error) {
+			return &box.File{ID: id, SHA1: "remote", ETag: "3"}, nil
+		},
+		downloadFile: func(id, destPath string) error {
+			return ioutil.WriteFile(destPath, []byte("remote "+id), 0644)
+		},
+		deleteFile: func(id, ifMatch string) error {
+			deleted = append(deleted, id+"@"+ifMatch)
+			switch id {
+			case "5":
+				return &box.APIError{StatusCode: http.StatusForbidden, Code: box.ErrorCodeAccessDenied}
+			case "6":
+				return &box.APIError{StatusCode: http.StatusPreconditionFailed, Code: box.ErrorCodePreconditionFailed}
+			}
+			return nil
+		},
+	}
+	c, cleanup := newTestCache(t, client)
+	defer cleanup()
+
+	_, err := c.db.Exec(`insert into files (Path, ID, SHA1, ETag, ParentID, Locked) values
+		('Box Sync/locked.txt', '5', 'old', '1', '0', 1),
+		('Box Sync/edited.txt', '6', 'old', '1', '0', 0),
+		('Box Sync/unknown.txt', '7', 'old', null, '0', 0),
+		('Box Sync/plain.txt', '8', 'remote', null, '0', 0);`)
+	assert.NoError(t, err, "Function should not return error")
+
+	err = c.rescanLocalTree()
+	assert.NoError(t, err, "Function should not return error")
+	sort.Strings(deleted)
+	assert.Equal(t, []string{"5@1", "6@1", "8@3"}, deleted, "Deletes should only succeed if the remote file is unchanged")
+
+	for name, id := range map[string]string{"locked.txt": "5", "edited.txt": "6", "unknown.txt": "7"} {
+		var etag string
+		err = c.db.QueryRow(`select ETag from files where ID = ?;`, id).Scan(&etag)
+		assert.NoError(t, err, "File that was not deleted should stay cached")
+		assert.Equal(t, "3", etag, "Restored version should be cached")
+		content, err := ioutil.ReadFile(filepath.Join(c.localRootDirectory, name))
+		assert.NoError(t, err, "File that was not deleted should be restored")
+		assert.Equal(t, "remote "+id, string(content), "Remote copy should be restored")
+	}
+	err = c.db.QueryRow(`select ID from files where ID = '8';`).Scan(new(string))
+	assert.Equal(t, sql.ErrNoRows, err, "Deleted file should not stay cached")
+}
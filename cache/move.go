@@ -0,0 +1,537 @@
+package cache
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/net/context"
+
+	"gitlab.engr.illinois.edu/sp-box/boxsync/box"
+	"gitlab.engr.illinois.edu/sp-box/boxsync/sync"
+)
+
+// localItem is a file or folder found while scanning the local tree.
+type localItem struct {
+	localPath  string
+	remotePath string
+	inode      int64
+}
+
+// cachedItem is the part of a files or folders row used to recognize an item
+// that was moved.
+type cachedItem struct {
+	ID    string
+	SHA1  string // Empty for folders, and the URL for web links.
+	Inode int64
+	ETag  string // Empty for folders and web links.
+}
+
+// syncLocalFolders creates the local folders that do not exist remotely yet,
+// unless they are cached folders that were moved or renamed, in which case
+// they are moved remotely. It returns the cached folders that no longer exist
+// locally, keyed by path.
+func (c *syncCache) syncLocalFolders(folders []localItem) (map[string]cachedItem, error) {
+	local := map[string]bool{}
+	for _, folder := range folders {
+		local[folder.remotePath] = true
+	}
+
+	cached, err := c.cachedItems(`select Path, coalesce(ID, ''), '', coalesce(Inode, 0), '' from folders;`)
+	if err != nil {
+		return nil, err
+	}
+	missing := missingItems(cached, local)
+
+	for _, folder := range folders {
+		if err := c.ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if item, ok := cached[folder.remotePath]; ok {
+			if item.Inode != folder.inode {
+				c.db.Exec(`update folders set Inode = ? where Path = ?;`, folder.inode, folder.remotePath)
+			}
+			continue
+		}
+
+		oldPath := findMovedItem(missing, folder.inode, "", filepath.Base(folder.remotePath))
+		if oldPath == "" {
+			oldPath = c.findRenamedFolder(missing, folder)
+		}
+		if oldPath != "" {
+			err := c.moveFolder(oldPath, missing[oldPath], folder)
+			if err == nil {
+				// Everything in the folder moved along with it.
+				cached, err = c.cachedItems(`select Path, coalesce(ID, ''), '', coalesce(Inode, 0), '' from folders;`)
+				if err != nil {
+					return nil, err
+				}
+				missing = missingItems(cached, local)
+				continue
+			}
+			log.Printf("Failed to move folder %s to %s: %v", oldPath, folder.remotePath, err)
+		}
+
+		_, err := c.addFolderToDB(folder.remotePath)
+		if err != nil {
+			log.Printf("Failed to create folder %s: %v", folder.remotePath, err)
+		}
+	}
+
+	return missing, nil
+}
+
+// syncLocalFiles uploads the local files that are new or changed, moving the
+// cached files that were moved or renamed instead of uploading them again. It
+// returns the cached files that no longer exist locally, keyed by path.
+func (c *syncCache) syncLocalFiles(files []localItem) (map[string]cachedItem, error) {
+	local := map[string]bool{}
+	for _, file := range files {
+		local[file.remotePath] = true
+	}
+
+	cached, err := c.cachedItems(`select Path, coalesce(ID, ''), coalesce(SHA1, ''), coalesce(Inode, 0), coalesce(ETag, '') from files;`)
+	if err != nil {
+		return nil, err
+	}
+	missing := missingItems(cached, local)
+
+	for _, file := range files {
+		if err := c.ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if item, ok := cached[file.remotePath]; ok {
+			if item.Inode != file.inode {
+				c.db.Exec(`update files set Inode = ? where Path = ?;`, file.inode, file.remotePath)
+			}
+			err := c.syncLocalFile(file.localPath, file.remotePath)
+			if err != nil {
+				log.Printf("Failed to sync %s: %v", file.localPath, err)
+			}
+			continue
+		}
+
+		var oldPath string
+		if len(missing) > 0 {
+			oldPath = findMovedItem(missing, file.inode, sync.SHA1(file.localPath), filepath.Base(file.remotePath))
+		}
+		if oldPath != "" {
+			err := c.moveFile(oldPath, missing[oldPath], file)
+			if err == nil {
+				delete(missing, oldPath)
+				// The file may have been edited as well as moved.
+				err = c.syncLocalFile(file.localPath, file.remotePath)
+				if err != nil {
+					log.Printf("Failed to sync %s: %v", file.localPath, err)
+				}
+				continue
+			}
+			log.Printf("Failed to move file %s to %s: %v", oldPath, file.remotePath, err)
+		}
+
+		_, err := c.AddFileToDB(file.localPath)
+		if err != nil {
+			log.Printf("Failed to upload %s: %v", file.localPath, err)
+		}
+	}
+
+	return missing, nil
+}
+
+// moveFolder moves the cached folder at oldPath to where folder is now, both
+// remotely and in the cache.
+func (c *syncCache) moveFolder(oldPath string, item cachedItem, folder localItem) error {
+	parentID, name, err := c.moveTarget(oldPath, folder.remotePath)
+	if err != nil {
+		return err
+	}
+
+	moved, err := c.client.MoveFolder(item.ID, parentID, name)
+	if err != nil {
+		return err
+	}
+	log.Printf("Moved folder %s to %s", oldPath, folder.remotePath)
+
+	err = c.renameCachedPaths(oldPath, folder.remotePath)
+	if err != nil {
+		return err
+	}
+	_, err = c.db.Exec(`update folders set SequenceID = ?, ParentID = ?, Inode = ? where Path = ?;`,
+		moved.SequenceID, parentID, folder.inode, folder.remotePath)
+	return err
+}
+
+// moveFile moves the cached file at oldPath to where file is now, both
+// remotely and in the cache. The move fails if the remote file changed since
+// it was last synced, in which case it is uploaded anew at its new path and
+// restored at the old one.
+func (c *syncCache) moveFile(oldPath string, item cachedItem, file localItem) error {
+	etag, err := c.syncedETag(item.ID, item.SHA1, item.ETag)
+	if err != nil {
+		return err
+	}
+	parentID, name, err := c.moveTarget(oldPath, file.remotePath)
+	if err != nil {
+		return err
+	}
+
+	moved, err := c.client.IfMatch(etag).MoveFile(item.ID, parentID, name)
+	if err != nil {
+		return err
+	}
+	log.Printf("Moved file %s to %s", oldPath, file.remotePath)
+
+	_, err = c.db.Exec(`update files set Path = ?, SequenceID = ?, ETag = ?, ParentID = ?, Inode = ? where Path = ?;`,
+		file.remotePath, moved.SequenceID, moved.ETag, parentID, file.inode, oldPath)
+	return err
+}
+
+// moveTarget returns the ID of the folder an item moved from oldPath to
+// newPath now belongs in, creating it if needed, and the item's new name, or
+// an empty name if it was not renamed.
+func (c *syncCache) moveTarget(oldPath, newPath string) (string, string, error) {
+	parentID, err := c.addFolderToDB(filepath.Dir(newPath))
+	if err != nil {
+		return "", "", err
+	}
+	name := filepath.Base(newPath)
+	if name == filepath.Base(oldPath) {
+		name = ""
+	}
+	return parentID, name, nil
+}
+
+// HandleEvent applies a change made remotely to the local tree. Moves and
+// renames are applied by renaming the local copy, and files locked by other
+// users are made read-only; other changes are picked up by the next refresh.
+func (c *syncCache) HandleEvent(ctx context.Context, event box.Event) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.withContext(ctx).handleEvent(event)
+}
+
+func (c *syncCache) handleEvent(event box.Event) error {
+	switch event.EventType {
+	case box.EventTypeItemMove, box.EventTypeItemRename:
+		if folder := event.SourceFolder(); folder != nil {
+			return c.applyRemoteMove("folders", folder.ID, folder.Name, folder.SequenceID, "", folder.Parent, folder.PathCollection)
+		}
+		if file := event.SourceFile(); file != nil {
+			return c.applyRemoteMove("files", file.ID, file.Name, file.SequenceID, file.ETag, file.Parent, file.PathCollection)
+		}
+	case box.EventTypeLockCreate, box.EventTypeLockDestroy:
+		if file := event.SourceFile(); file != nil {
+			var lock *box.Lock
+			if event.EventType == box.EventTypeLockCreate && event.CreatedBy.ID != c.userID {
+				// The source may not include the lock, which is
+				// then taken not to expire.
+				lock = file.Lock
+				if lock == nil {
+					lock = &box.Lock{}
+				}
+			}
+			return c.applyRemoteLock(file.ID, lock)
+		}
+	}
+	return nil
+}
+
+// applyRemoteMove moves the local copy of the item id of table to match its
+// new name and parent, whose path is pathCollection. etag is the new ETag of a
+// file.
+func (c *syncCache) applyRemoteMove(table, id, name, sequenceID, etag string, parent *box.Folder, pathCollection box.Collection) error {
+	oldPath, err := c.cachedPath(table, id)
+	if err == sql.ErrNoRows {
+		// The item is not synced, e.g. it was moved in from outside the
+		// sync root, so it is left for the next refresh.
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if oldPath == filepath.Base(c.remoteRootDirectory) {
+		return nil
+	}
+
+	var parentPath string
+	if parent != nil {
+		parentPath, err = c.cachedPath("folders", parent.ID)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+	}
+	if parentPath == "" {
+		if len(pathCollection.Entries) == 0 {
+			// Without its path, the item cannot be told to have left
+			// the sync root, so it is left for the next refresh.
+			return nil
+		}
+		// The new parent may have been created remotely since the last
+		// refresh.
+		parentPath, err = c.cacheParentFolders(pathCollection)
+		if err != nil {
+			return err
+		}
+	}
+	if parentPath == "" {
+		log.Printf("%s was moved out of %s remotely, removing the local copy", oldPath, c.remoteRootDirectory)
+		err = os.RemoveAll(c.localPath(oldPath))
+		if err != nil {
+			return err
+		}
+		return c.removeCachedPaths(oldPath)
+	}
+
+	// Moves made by this client come back as events too, and are already
+	// reflected in the cache.
+	newPath := filepath.Join(parentPath, name)
+	if newPath == oldPath {
+		return nil
+	}
+
+	oldLocalPath, newLocalPath := c.localPath(oldPath), c.localPath(newPath)
+	if _, err := os.Lstat(newLocalPath); err == nil {
+		return fmt.Errorf("cannot move %s to %s: destination already exists", oldLocalPath, newLocalPath)
+	}
+	err = os.MkdirAll(filepath.Dir(newLocalPath), 0755)
+	if err != nil {
+		return err
+	}
+	// A local copy that is already gone is deleted remotely by the next
+	// scan, at its new path.
+	err = os.Rename(oldLocalPath, newLocalPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	log.Printf("Moved %s to %s", oldLocalPath, newLocalPath)
+
+	err = c.renameCachedPaths(oldPath, newPath)
+	if err != nil {
+		return err
+	}
+	_, err = c.db.Exec(`update `+table+` set SequenceID = ?, ParentID = ?, Inode = ? where Path = ?;`,
+		sequenceID, parent.ID, localInode(newLocalPath), newPath)
+	if err != nil || table != "files" {
+		return err
+	}
+	// Box gives a file a new ETag when it is moved, which the next upload
+	// of its local changes is conditional on.
+	_, err = c.db.Exec(`update files set ETag = ? where Path = ?;`, etag, newPath)
+	return err
+}
+
+// cachedItems returns the rows selected by query with args, which must select a path,
+// ID, SHA1, inode number and ETag, keyed by path.
+func (c *syncCache) cachedItems(query string, args ...interface{}) (map[string]cachedItem, error) {
+	rows, err := c.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := map[string]cachedItem{}
+	for rows.Next() {
+		var pathName string
+		var item cachedItem
+		err := rows.Scan(&pathName, &item.ID, &item.SHA1, &item.Inode, &item.ETag)
+		if err != nil {
+			return nil, err
+		}
+		items[pathName] = item
+	}
+	return items, rows.Err()
+}
+
+// cachedPath returns the path of the item id in table.
+func (c *syncCache) cachedPath(table, id string) (string, error) {
+	var pathName string
+	err := c.db.QueryRow(`select Path from `+table+` where ID = ?;`, id).Scan(&pathName)
+	return pathName, err
+}
+
+// renameCachedPaths changes the path of the item at oldPath, and of
+// everything in it if it is a folder, to newPath.
+func (c *syncCache) renameCachedPaths(oldPath, newPath string) error {
+	tx, err := c.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	oldPrefix := oldPath + string(filepath.Separator)
+	newPrefix := newPath + string(filepath.Separator)
+	for _, table := range []string{"folders", "files", "weblinks"} {
+		_, err = tx.Exec(`update `+table+` set Path = ? where Path = ?;`, newPath, oldPath)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		_, err = tx.Exec(`update `+table+` set Path = ? || substr(Path, length(?) + 1) where substr(Path, 1, length(?)) = ?;`,
+			newPrefix, oldPrefix, oldPrefix, oldPrefix)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// cacheParentFolders returns the cached path of the last folder in
+// pathCollection, the path of an item, or an empty path if the item is not in
+// the sync root. The folders in the sync root that are not cached yet are
+// cached and created locally.
+func (c *syncCache) cacheParentFolders(pathCollection box.Collection) (string, error) {
+	rootPath := filepath.Base(c.remoteRootDirectory)
+	var rootID string
+	err := c.db.QueryRow(`select ID from folders where Path = ?;`, rootPath).Scan(&rootID)
+	if err != nil {
+		return "", err
+	}
+
+	var parentPath, parentID string
+	for _, entry := range pathCollection.Entries {
+		var folder box.Folder
+		err := json.Unmarshal(entry, &folder)
+		if err != nil {
+			return "", err
+		}
+		if folder.ID == rootID {
+			parentPath, parentID = rootPath, rootID
+			continue
+		} else if parentPath == "" {
+			continue
+		}
+
+		folderPath, err := c.cachedPath("folders", folder.ID)
+		if err == sql.ErrNoRows {
+			folderPath = filepath.Join(parentPath, folder.Name)
+			err = os.MkdirAll(c.localPath(folderPath), 0755)
+			if err != nil {
+				return "", err
+			}
+			_, err = c.db.Exec(`insert into folders (Path, ID, Valid, ParentID, Inode) values (?, ?, ?, ?, ?);`,
+				folderPath, folder.ID, true, parentID, localInode(c.localPath(folderPath)))
+		}
+		if err != nil {
+			return "", err
+		}
+		parentPath, parentID = folderPath, folder.ID
+	}
+	return parentPath, nil
+}
+
+// removeCachedPaths removes the item at remotePath, and everything in it if
+// it is a folder, from the cache.
+func (c *syncCache) removeCachedPaths(remotePath string) error {
+	prefix := remotePath + string(filepath.Separator)
+	for _, table := range []string{"weblinks", "files", "folders"} {
+		_, err := c.db.Exec(`delete from `+table+` where Path = ? or substr(Path, 1, length(?)) = ?;`,
+			remotePath, prefix, prefix)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// remotePath returns the path in the cache of the local item at localPath.
+func (c *syncCache) remotePath(localPath string) string {
+	relPath, err := filepath.Rel(c.localRootDirectory, localPath)
+	if err != nil {
+		relPath = localPath
+	}
+	return filepath.Join(filepath.Base(c.remoteRootDirectory), relPath)
+}
+
+// localPath returns the local path of the item at remotePath in the cache.
+func (c *syncCache) localPath(remotePath string) string {
+	relPath, err := filepath.Rel(filepath.Base(c.remoteRootDirectory), remotePath)
+	if err != nil {
+		relPath = remotePath
+	}
+	return filepath.Join(c.localRootDirectory, relPath)
+}
+
+// findRenamedFolder returns the path of the missing folder with the inode of
+// folder that folder still holds an item of, or "" if there is none. This
+// tells a renamed folder apart from a new one that reused the inode of a
+// deleted folder.
+func (c *syncCache) findRenamedFolder(missing map[string]cachedItem, folder localItem) string {
+	if folder.inode == 0 {
+		return ""
+	}
+	for pathName, item := range missing {
+		if item.Inode != folder.inode {
+			continue
+		}
+		rows, err := c.db.Query(`select Path from files where ParentID = ? union select Path from folders where ParentID = ?;`, item.ID, item.ID)
+		if err != nil {
+			return ""
+		}
+		for rows.Next() {
+			var childPath string
+			rows.Scan(&childPath)
+			if _, err := os.Lstat(filepath.Join(folder.localPath, filepath.Base(childPath))); err == nil {
+				rows.Close()
+				return pathName
+			}
+		}
+		rows.Close()
+	}
+	return ""
+}
+
+// localInode returns the inode number of the local item at localPath, or 0 if
+// it cannot be read.
+func localInode(localPath string) int64 {
+	info, err := os.Lstat(localPath)
+	if err != nil {
+		return 0
+	}
+	return inode(info)
+}
+
+// missingItems returns the items of cached whose paths are not in local.
+func missingItems(cached map[string]cachedItem, local map[string]bool) map[string]cachedItem {
+	missing := map[string]cachedItem{}
+	for pathName, item := range cached {
+		if !local[pathName] {
+			missing[pathName] = item
+		}
+	}
+	return missing
+}
+
+// findMovedItem returns the path of the missing item that the local item with
+// the inode number inode, content hash sha1 and name was moved from, or "" if
+// it is new. An item is matched by inode only if its content or its name is
+// unchanged, since the inode of a deleted item is soon reused by unrelated new
+// ones; failing that a file is matched to the only missing file whose content
+// has the hash sha1. Zero and empty values never match.
+func findMovedItem(missing map[string]cachedItem, inode int64, sha1, name string) string {
+	if inode != 0 {
+		for pathName, item := range missing {
+			if item.Inode == inode && (sha1 != "" && item.SHA1 == sha1 || filepath.Base(pathName) == name) {
+				return pathName
+			}
+		}
+	}
+	if sha1 == "" {
+		return ""
+	}
+
+	var match string
+	for pathName, item := range missing {
+		if item.SHA1 == sha1 {
+			if match != "" {
+				return ""
+			}
+			match = pathName
+		}
+	}
+	return match
+}
@@ -0,0 +1,287 @@
+package cache
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gitlab.engr.illinois.edu/sp-box/boxsync/box"
+	"gitlab.engr.illinois.edu/sp-box/boxsync/sync"
+)
+
+func TestFindMovedItem(t *testing.T) {
+	missing := map[string]cachedItem{
+		"Box Sync/a.txt": {ID: "1", SHA1: "aaa", Inode: 10},
+		"Box Sync/b.txt": {ID: "2", SHA1: "bbb", Inode: 11},
+		"Box Sync/c.txt": {ID: "3", SHA1: "bbb", Inode: 12},
+	}
+
+	assert.Equal(t, "Box Sync/b.txt", findMovedItem(missing, 11, "bbb", "d.txt"), "Inode and hash should match first")
+	assert.Equal(t, "Box Sync/b.txt", findMovedItem(missing, 11, "zzz", "b.txt"), "Inode should match a file edited & moved without renaming")
+	assert.Equal(t, "", findMovedItem(missing, 11, "zzz", "d.txt"), "Reused inode should not match a new file")
+	assert.Equal(t, "Box Sync/a.txt", findMovedItem(missing, 11, "aaa", "d.txt"), "Unique hash should match")
+	assert.Equal(t, "Box Sync/a.txt", findMovedItem(missing, 99, "aaa", "d.txt"), "Unique hash should match without inode")
+	assert.Equal(t, "", findMovedItem(missing, 99, "bbb", "d.txt"), "Ambiguous hash should not match")
+	assert.Equal(t, "Box Sync/c.txt", findMovedItem(missing, 12, "", "c.txt"), "Inode and name should match a folder")
+	assert.Equal(t, "", findMovedItem(missing, 12, "", "d"), "Reused inode should not match a new folder")
+	assert.Equal(t, "", findMovedItem(missing, 0, "", ""), "Unknown inode and hash should not match")
+}
+
+func TestRenameCachedPaths(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	assert.NoError(t, err, "Function should not return error")
+	defer db.Close()
+	// Every statement must see the same in-memory database.
+	db.SetMaxOpenConns(1)
+
+	_, err = db.Exec(`create table folders (Path text not null primary key, ID text unique, Valid boolean, SequenceID text, ParentID text, Inode integer);
+	create table files (Path text not null primary key, ID text unique, SHA1 text, Valid boolean, SequenceID text, ETag text, ParentID text, Inode integer);
+	create table weblinks (Path text not null primary key, ID text unique, URL text, SequenceID text, ParentID text, Inode integer);`)
+	assert.NoError(t, err, "Function should not return error")
+
+	for _, p := range []string{"Box Sync", "Box Sync/old", "Box Sync/old/sub", "Box Sync/older"} {
+		_, err = db.Exec(`insert into folders (Path, ID) values (?, ?);`, filepath.FromSlash(p), p)
+		assert.NoError(t, err, "Function should not return error")
+	}
+	for _, p := range []string{"Box Sync/old/a.txt", "Box Sync/old/sub/b.txt", "Box Sync/older/c.txt"} {
+		_, err = db.Exec(`insert into files (Path, ID) values (?, ?);`, filepath.FromSlash(p), p)
+		assert.NoError(t, err, "Function should not return error")
+	}
+
+	c := &syncCache{db: db}
+	err = c.renameCachedPaths(filepath.FromSlash("Box Sync/old"), filepath.FromSlash("Box Sync/new"))
+	assert.NoError(t, err, "Function should not return error")
+
+	expected := map[string]string{
+		"Box Sync/old":           "Box Sync/new",
+		"Box Sync/old/sub":       "Box Sync/new/sub",
+		"Box Sync/older":         "Box Sync/older",
+		"Box Sync/old/a.txt":     "Box Sync/new/a.txt",
+		"Box Sync/old/sub/b.txt": "Box Sync/new/sub/b.txt",
+		"Box Sync/older/c.txt":   "Box Sync/older/c.txt",
+	}
+	for id, p := range expected {
+		var pathName string
+		err := db.QueryRow(`select Path from folders where ID = ? union select Path from files where ID = ?;`, id, id).Scan(&pathName)
+		assert.NoError(t, err, "Function should not return error")
+		assert.Equal(t, filepath.FromSlash(p), pathName, "Path of %s should be renamed", id)
+	}
+}
+
+func TestRescanMovesRenamedFile(t *testing.T) {
+	var moves []string
+	client := &fakeClient{
+		moveFile: func(id, parentID, name, ifMatch string) (*box.File, error) {
+			moves = append(moves, id+" "+parentID+" "+name+"@"+ifMatch)
+			return &box.File{ID: id, SequenceID: "2", ETag: "2"}, nil
+		},
+	}
+	c, cleanup := newTestCache(t, client)
+	defer cleanup()
+
+	localPath := writeLocalFile(t, c, filepath.Join("docs", "b.txt"), "report")
+	_, err := c.db.Exec(`insert into folders (Path, ID, ParentID, Inode) values (?, '7', '0', ?);
+	insert into files (Path, ID, SHA1, ETag, ParentID, Inode) values (?, '5', ?, '1', '7', ?);`,
+		filepath.Join("Box Sync", "docs"), localInode(filepath.Dir(localPath)),
+		filepath.Join("Box Sync", "docs", "a.txt"), sync.SHA1(localPath), localInode(localPath))
+	assert.NoError(t, err, "Function should not return error")
+
+	err = c.rescanLocalTree()
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, []string{"5 7 b.txt@1"}, moves, "Renamed file should be renamed remotely if unchanged there")
+
+	var pathName, etag string
+	err = c.db.QueryRow(`select Path, ETag from files where ID = '5';`).Scan(&pathName, &etag)
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, filepath.Join("Box Sync", "docs", "b.txt"), pathName, "Cached path should follow the file")
+	assert.Equal(t, "2", etag, "ETag of the moved file should be cached")
+}
+
+func TestRescanMovesFolder(t *testing.T) {
+	var moves []string
+	client := &fakeClient{
+		moveFolder: func(id, parentID, name string) (*box.Folder, error) {
+			moves = append(moves, id+" "+parentID+" "+name)
+			return &box.Folder{ID: id, SequenceID: "2"}, nil
+		},
+	}
+	c, cleanup := newTestCache(t, client)
+	defer cleanup()
+
+	localPath := writeLocalFile(t, c, filepath.Join("dest", "src", "x.txt"), "data")
+	srcPath, destPath := filepath.Dir(localPath), filepath.Dir(filepath.Dir(localPath))
+	_, err := c.db.Exec(`insert into folders (Path, ID, ParentID, Inode) values (?, '7', '0', ?), (?, '8', '0', ?);
+	insert into files (Path, ID, SHA1, ParentID, Inode) values (?, '5', ?, '7', ?);`,
+		filepath.Join("Box Sync", "src"), localInode(srcPath), filepath.Join("Box Sync", "dest"), localInode(destPath),
+		filepath.Join("Box Sync", "src", "x.txt"), sync.SHA1(localPath), localInode(localPath))
+	assert.NoError(t, err, "Function should not return error")
+
+	err = c.rescanLocalTree()
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, []string{"7 8 "}, moves, "Moved folder should be moved remotely without renaming")
+
+	var pathName string
+	err = c.db.QueryRow(`select Path from files where ID = '5';`).Scan(&pathName)
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, filepath.Join("Box Sync", "dest", "src", "x.txt"), pathName, "Files should move along with their folder")
+}
+
+func TestRescanIgnoresReusedInode(t *testing.T) {
+	var uploaded, deleted []string
+	client := &fakeClient{
+		uploadFile: func(srcPath, parentID string) (*box.File, error) {
+			uploaded = append(uploaded, filepath.Base(srcPath))
+			return &box.File{ID: "6"}, nil
+		},
+		deleteFile: func(id, ifMatch string) error {
+			deleted = append(deleted, id+"@"+ifMatch)
+			return nil
+		},
+	}
+	c, cleanup := newTestCache(t, client)
+	defer cleanup()
+
+	// b.txt was created right after a.txt was deleted, reusing its inode.
+	localPath := writeLocalFile(t, c, "b.txt", "unrelated")
+	_, err := c.db.Exec(`insert into files (Path, ID, SHA1, ETag, ParentID, Inode) values (?, '5', 'old', '1', '0', ?);`,
+		filepath.Join("Box Sync", "a.txt"), localInode(localPath))
+	assert.NoError(t, err, "Function should not return error")
+
+	err = c.rescanLocalTree()
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, []string{"b.txt"}, uploaded, "New file should be uploaded")
+	assert.Equal(t, []string{"5@1"}, deleted, "Deleted file should be deleted remotely if unchanged there")
+}
+
+func TestHandleMoveEvents(t *testing.T) {
+	c, cleanup := newTestCache(t, &fakeClient{})
+	defer cleanup()
+
+	writeLocalFile(t, c, "a.txt", "a")
+	writeLocalFile(t, c, filepath.Join("src", "x.txt"), "x")
+	err := os.Mkdir(filepath.Join(c.localRootDirectory, "dest"), 0755)
+	assert.NoError(t, err, "Function should not return error")
+	_, err = c.db.Exec(`insert into folders (Path, ID, ParentID) values (?, '7', '0'), (?, '8', '0');
+	insert into files (Path, ID, ParentID, ETag) values (?, '5', '0', '1'), (?, '6', '7', '1');`,
+		filepath.Join("Box Sync", "src"), filepath.Join("Box Sync", "dest"),
+		filepath.Join("Box Sync", "a.txt"), filepath.Join("Box Sync", "src", "x.txt"))
+	assert.NoError(t, err, "Function should not return error")
+
+	err = c.handleEvent(box.Event{
+		EventType: box.EventTypeItemRename,
+		Source:    []byte(`{"type": "file", "id": "5", "name": "b.txt", "sequence_id": "2", "etag": "2", "parent": {"type": "folder", "id": "0"}}`),
+	})
+	assert.NoError(t, err, "Function should not return error")
+	err = c.handleEvent(box.Event{
+		EventType: box.EventTypeItemMove,
+		Source:    []byte(`{"type": "folder", "id": "7", "name": "src", "sequence_id": "2", "parent": {"type": "folder", "id": "8"}}`),
+	})
+	assert.NoError(t, err, "Function should not return error")
+
+	for id, p := range map[string]string{"5": "b.txt", "6": filepath.Join("dest", "src", "x.txt")} {
+		var pathName string
+		err := c.db.QueryRow(`select Path from files where ID = ?;`, id).Scan(&pathName)
+		assert.NoError(t, err, "Function should not return error")
+		assert.Equal(t, filepath.Join("Box Sync", p), pathName, "Cached path of %s should follow the remote move", id)
+		_, err = os.Stat(filepath.Join(c.localRootDirectory, p))
+		assert.NoError(t, err, "Local copy of %s should be moved", id)
+	}
+	_, err = os.Stat(filepath.Join(c.localRootDirectory, "a.txt"))
+	assert.True(t, os.IsNotExist(err), "Renamed file should not be left at its old path")
+	var etag string
+	err = c.db.QueryRow(`select ETag from files where ID = '5';`).Scan(&etag)
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, "2", etag, "New ETag of the renamed file should be cached")
+}
+
+func TestHandleMoveIntoNewFolder(t *testing.T) {
+	c, cleanup := newTestCache(t, &fakeClient{})
+	defer cleanup()
+
+	writeLocalFile(t, c, filepath.Join("src", "x.txt"), "x")
+	writeLocalFile(t, c, "gone.txt", "unsynced edit")
+	_, err := c.db.Exec(`insert into folders (Path, ID, ParentID) values (?, '7', '0');
+	insert into files (Path, ID, ParentID) values (?, '6', '7'), (?, '5', '0');`,
+		filepath.Join("Box Sync", "src"), filepath.Join("Box Sync", "src", "x.txt"), filepath.Join("Box Sync", "gone.txt"))
+	assert.NoError(t, err, "Function should not return error")
+
+	// The folders 12 and 13 were created remotely since the last refresh.
+	err = c.handleEvent(box.Event{
+		EventType: box.EventTypeItemMove,
+		Source: []byte(`{"type": "folder", "id": "7", "name": "src", "sequence_id": "2", "parent": {"type": "folder", "id": "13"},
+			"path_collection": {"total_count": 3, "entries": [{"type": "folder", "id": "0", "name": "Box Sync"},
+				{"type": "folder", "id": "12", "name": "new"}, {"type": "folder", "id": "13", "name": "newer"}]}}`),
+	})
+	assert.NoError(t, err, "Function should not return error")
+
+	newPath := filepath.Join("new", "newer", "src", "x.txt")
+	content, err := ioutil.ReadFile(filepath.Join(c.localRootDirectory, newPath))
+	assert.NoError(t, err, "Local copy should be moved into the new folder")
+	assert.Equal(t, "x", string(content), "Local copy should be kept")
+	var pathName string
+	err = c.db.QueryRow(`select Path from files where ID = '6';`).Scan(&pathName)
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, filepath.Join("Box Sync", newPath), pathName, "Cached path should follow the remote move")
+	err = c.db.QueryRow(`select Path from folders where ID = '13';`).Scan(&pathName)
+	assert.NoError(t, err, "New parent should be cached")
+	assert.Equal(t, filepath.Join("Box Sync", "new", "newer"), pathName, "New parent should be cached at its path")
+
+	// Without a path, a move to an unknown folder is left for the refresh.
+	err = c.handleEvent(box.Event{
+		EventType: box.EventTypeItemMove,
+		Source:    []byte(`{"type": "file", "id": "5", "name": "gone.txt", "parent": {"type": "folder", "id": "30"}}`),
+	})
+	assert.NoError(t, err, "Function should not return error")
+	_, err = os.Stat(filepath.Join(c.localRootDirectory, "gone.txt"))
+	assert.NoError(t, err, "File should not be removed without knowing where it went")
+
+	err = c.handleEvent(box.Event{
+		EventType: box.EventTypeItemMove,
+		Source: []byte(`{"type": "file", "id": "5", "name": "gone.txt", "parent": {"type": "folder", "id": "30"},
+			"path_collection": {"total_count": 1, "entries": [{"type": "folder", "id": "30", "name": "Elsewhere"}]}}`),
+	})
+	assert.NoError(t, err, "Function should not return error")
+	_, err = os.Stat(filepath.Join(c.localRootDirectory, "gone.txt"))
+	assert.True(t, os.IsNotExist(err), "File moved out of the sync root should be removed locally")
+}
+
+func TestRescanRenamesFolder(t *testing.T) {
+	var moves, created, deleted []string
+	client := &fakeClient{
+		moveFolder: func(id, parentID, name string) (*box.Folder, error) {
+			moves = append(moves, id+" "+parentID+" "+name)
+			return &box.Folder{ID: id, SequenceID: "2"}, nil
+		},
+		createFolder: func(name, parentID string) (*box.Folder, error) {
+			created = append(created, name)
+			return &box.Folder{ID: "20"}, nil
+		},
+		deleteFolder: func(id string) error {
+			deleted = append(deleted, id)
+			return nil
+		},
+	}
+	c, cleanup := newTestCache(t, client)
+	defer cleanup()
+
+	// renamed was src, and empty reused the inode of the deleted old.
+	localPath := writeLocalFile(t, c, filepath.Join("renamed", "x.txt"), "data")
+	emptyPath := filepath.Join(c.localRootDirectory, "empty")
+	err := os.Mkdir(emptyPath, 0755)
+	assert.NoError(t, err, "Function should not return error")
+	_, err = c.db.Exec(`insert into folders (Path, ID, ParentID, Inode) values (?, '7', '0', ?), (?, '8', '0', ?);
+	insert into files (Path, ID, SHA1, ParentID, Inode) values (?, '5', ?, '7', ?);`,
+		filepath.Join("Box Sync", "src"), localInode(filepath.Dir(localPath)), filepath.Join("Box Sync", "old"), localInode(emptyPath),
+		filepath.Join("Box Sync", "src", "x.txt"), sync.SHA1(localPath), localInode(localPath))
+	assert.NoError(t, err, "Function should not return error")
+
+	err = c.rescanLocalTree()
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, []string{"7 0 renamed"}, moves, "Renamed folder should be renamed remotely")
+	assert.Equal(t, []string{"empty"}, created, "New folder should be created")
+	assert.Equal(t, []string{"8"}, deleted, "Deleted folder should be deleted remotely")
+}
@@ -0,0 +1,176 @@
+package cache
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+
+	"gitlab.engr.illinois.edu/sp-box/boxsync/box"
+)
+
+// fakeClient is a box.Client whose calls are answered by the functions a test
+// sets. Calling any other method panics through the nil embedded Client.
+type fakeClient struct {
+	box.Client
+	threshold int64
+	ifMatch   string // The ETag set by IfMatch.
+
+	getFile                    func(id string) (*box.File, error)
+	downloadFile               func(id, destPath string) error
+	preflightUpload            func(name, parentID string) error // Accepts every upload if not set.
+	preflightUploadVersion     func(fileID string) error         // Accepts every upload if not set.
+	uploadFile                 func(srcPath, parentID string) (*box.File, error)
+	uploadFileVersion          func(fileID, srcPath, ifMatch string) (*box.File, error)
+	moveFile                   func(id, parentID, name, ifMatch string) (*box.File, error)
+	moveFolder                 func(id, parentID, name string) (*box.Folder, error)
+	createFolder               func(name, parentID string) (*box.Folder, error)
+	deleteFolder               func(id string) error
+	deleteFile                 func(id, ifMatch string) error
+	createWebLink              func(url, parentID, name string) (*box.WebLink, error)
+	createUploadSession        func(parentID, name string) (*box.UploadSession, error)
+	createUploadSessionVersion func(fileID string) (*box.UploadSession, error)
+	uploadPart                 func(sessionID string, offset int64) (*box.UploadPart, error)
+	listUploadParts            func(sessionID string) ([]box.UploadPart, error)
+	commitUploadSession        func(sessionID, ifMatch string) (*box.File, error)
+	abortUploadSession         func(sessionID string) error
+}
+
+func (f *fakeClient) WithContext(ctx context.Context) box.Client {
+	return f
+}
+
+func (f *fakeClient) IfMatch(etag string) box.Client {
+	f2 := *f
+	f2.ifMatch = etag
+	return &f2
+}
+
+func (f *fakeClient) GetFile(id string) (*box.File, error) {
+	return f.getFile(id)
+}
+
+func (f *fakeClient) DownloadFile(id, destPath string) error {
+	return f.downloadFile(id, destPath)
+}
+
+func (f *fakeClient) PreflightUpload(name, parentID string, size int64) error {
+	if f.preflightUpload == nil {
+		return nil
+	}
+	return f.preflightUpload(name, parentID)
+}
+
+func (f *fakeClient) PreflightUploadVersion(fileID, name string, size int64) error {
+	if f.preflightUploadVersion == nil {
+		return nil
+	}
+	return f.preflightUploadVersion(fileID)
+}
+
+func (f *fakeClient) UploadFile(srcPath, parentID string) (*box.File, error) {
+	return f.uploadFile(srcPath, parentID)
+}
+
+func (f *fakeClient) UploadFileVersion(fileID, srcPath string) (*box.File, error) {
+	return f.uploadFileVersion(fileID, srcPath, f.ifMatch)
+}
+
+func (f *fakeClient) MoveFile(id, parentID, name string) (*box.File, error) {
+	return f.moveFile(id, parentID, name, f.ifMatch)
+}
+
+func (f *fakeClient) MoveFolder(id, parentID, name string) (*box.Folder, error) {
+	return f.moveFolder(id, parentID, name)
+}
+
+func (f *fakeClient) CreateFolder(name, parentID string) (*box.Folder, error) {
+	return f.createFolder(name, parentID)
+}
+
+func (f *fakeClient) DeleteFolder(id string, recursive bool) error {
+	return f.deleteFolder(id)
+}
+
+func (f *fakeClient) DeleteFile(id string) error {
+	return f.deleteFile(id, f.ifMatch)
+}
+
+func (f *fakeClient) CreateWebLink(url, parentID, name, description string) (*box.WebLink, error) {
+	return f.createWebLink(url, parentID, name)
+}
+
+func (f *fakeClient) ChunkedUploadThreshold() int64 {
+	if f.threshold == 0 {
+		return box.DefaultChunkedUploadThreshold
+	}
+	return f.threshold
+}
+
+func (f *fakeClient) CreateUploadSession(parentID, name string, size int64) (*box.UploadSession, error) {
+	return f.createUploadSession(parentID, name)
+}
+
+func (f *fakeClient) UploadPart(sessionID string, data []byte, offset, fileSize int64) (*box.UploadPart, error) {
+	return f.uploadPart(sessionID, offset)
+}
+
+func (f *fakeClient) CreateUploadSessionVersion(fileID string, size int64) (*box.UploadSession, error) {
+	return f.createUploadSessionVersion(fileID)
+}
+
+func (f *fakeClient) ListUploadParts(sessionID string) ([]box.UploadPart, error) {
+	return f.listUploadParts(sessionID)
+}
+
+func (f *fakeClient) CommitUploadSession(sessionID, sha1 string, parts []box.UploadPart) (*box.File, error) {
+	return f.commitUploadSession(sessionID, f.ifMatch)
+}
+
+func (f *fakeClient) AbortUploadSession(sessionID string) error {
+	return f.abortUploadSession(sessionID)
+}
+
+// newTestCache returns a cache of an empty local root, which the returned
+// function removes, whose requests are answered by client. The root is cached
+// as the folder 0.
+func newTestCache(t *testing.T, client box.Client) (*syncCache, func()) {
+	localRoot, err := ioutil.TempDir("", "boxsync")
+	assert.NoError(t, err, "Function should not return error")
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	assert.NoError(t, err, "Function should not return error")
+	db.SetMaxOpenConns(1)
+	err = createCacheTables(db)
+	assert.NoError(t, err, "Function should not return error")
+
+	c := &syncCache{
+		client:              client,
+		db:                  db,
+		localRootDirectory:  localRoot,
+		remoteRootDirectory: "Box Sync",
+		ctx:                 context.Background(),
+		userID:              "1",
+	}
+	_, err = db.Exec(`insert into folders (Path, ID, Valid) values ('Box Sync', '0', 1);`)
+	assert.NoError(t, err, "Function should not return error")
+	return c, func() {
+		db.Close()
+		os.RemoveAll(localRoot)
+	}
+}
+
+// writeLocalFile writes content to name in the local root of c and returns its
+// path.
+func writeLocalFile(t *testing.T, c *syncCache, name, content string) string {
+	localPath := filepath.Join(c.localRootDirectory, name)
+	err := os.MkdirAll(filepath.Dir(localPath), 0755)
+	assert.NoError(t, err, "Function should not return error")
+	err = ioutil.WriteFile(localPath, []byte(content), 0644)
+	assert.NoError(t, err, "Function should not return error")
+	return localPath
+}
@@ -0,0 +1,462 @@
+package cache
+
+import (
+	"database/sql"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gitlab.engr.illinois.edu/sp-box/boxsync/box"
+	"gitlab.engr.illinois.edu/sp-box/boxsync/sync"
+)
+
+// UploadSessionEntry is a chunked upload that was started but not yet
+// committed. It is keyed by the local path of the file being uploaded.
+type UploadSessionEntry struct {
+	Path      string
+	SessionID string
+	FileID    string // Set when uploading a new version of an existing file.
+	ParentID  string // Set when uploading a new file.
+	ETag      string // The ETag of the version a new version replaces.
+	SHA1      string
+	Size      int64
+	ModTime   int64
+	PartSize  int64
+}
+
+func createUploadTables(db *sql.DB) error {
+	sqlStmt := `create table if not exists upload_sessions
+	(Path text not null primary key,
+	SessionID text not null unique,
+	FileID text,
+	ParentID text,
+	ETag text,
+	SHA1 text,
+	Size integer,
+	ModTime integer,
+	PartSize integer);`
+
+	_, err := db.Exec(sqlStmt)
+	if err != nil {
+		log.Print("Failed creating the upload_sessions table")
+		return err
+	}
+
+	// Sessions recorded before the ETag was kept are committed
+	// unconditionally.
+	if _, err := db.Exec(`select ETag from upload_sessions limit 0;`); err != nil {
+		_, err = db.Exec(`alter table upload_sessions add column ETag text;`)
+		if err != nil {
+			log.Print("Failed adding the ETag column to the upload_sessions table")
+			return err
+		}
+	}
+
+	sqlStmt = `create table if not exists upload_parts
+	(SessionID text not null,
+	PartID text,
+	Offset integer not null,
+	Size integer,
+	SHA1 text,
+	primary key (SessionID, Offset),
+	FOREIGN KEY(SessionID) REFERENCES upload_sessions(SessionID));`
+
+	_, err = db.Exec(sqlStmt)
+	if err != nil {
+		log.Print("Failed creating the upload_parts table")
+		return err
+	}
+
+	return nil
+}
+
+// uploadFile uploads the local file at localPath, either as a new file in the
+// folder parentID or, if fileID is not empty, as a new version of fileID that
+// Box only accepts if the file still has etag, unless etag is empty.
+// Files large enough to need a chunked upload are uploaded through a session
+// that is recorded in the database so that it can be resumed after a restart.
+// It returns a nil file if Box would not accept the upload, which is then
+// skipped until the file changes.
+func (c *syncCache) uploadFile(localPath, parentID, fileID, etag string) (*box.File, error) {
+	fi, err := os.Stat(localPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// An upload that was already started was accepted when it began.
+	if !c.hasPendingUpload(localPath) {
+		accepted, err := c.preflightUpload(localPath, parentID, fileID, fi)
+		if err != nil || !accepted {
+			return nil, err
+		}
+	}
+
+	if fi.Size() < c.client.ChunkedUploadThreshold() {
+		err = c.discardUploadSession(localPath)
+		if err != nil {
+			return nil, err
+		}
+		if fileID != "" {
+			return c.client.IfMatch(etag).UploadFileVersion(fileID, localPath)
+		}
+		return c.client.UploadFile(localPath, parentID)
+	}
+
+	entry, parts, err := c.resumableUploadSession(localPath, parentID, fileID, etag, fi)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := c.uploadMissingParts(entry, parts)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.deleteUploadSession(entry.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// preflightUpload asks Box whether the upload would be accepted before any
+// content is sent, and reports whether it should go ahead. A name conflict is
+// returned as is so that the caller can hand it to the conflict handler, while
+// a file that does not fit in the account or whose name Box does not allow is
+// recorded as skipped, and not checked again until it is changed or the cache
+// is restarted.
+func (c *syncCache) preflightUpload(localPath, parentID, fileID string, fi os.FileInfo) (bool, error) {
+	skipped, err := c.uploadSkipped(localPath, fi)
+	if err != nil || skipped {
+		return false, err
+	}
+
+	if fileID != "" {
+		err = c.client.PreflightUploadVersion(fileID, "", fi.Size())
+	} else {
+		err = c.client.PreflightUpload(filepath.Base(localPath), parentID, fi.Size())
+	}
+	if box.IsQuotaExceeded(err) {
+		log.Printf("Skipping upload of %s: %d bytes would exceed the account's upload limit", localPath, fi.Size())
+	} else if box.IsItemNameInvalid(err) {
+		log.Printf("Skipping upload of %s: Box does not allow its name", localPath)
+	} else {
+		return err == nil, err
+	}
+
+	_, err = c.db.Exec(`insert or replace into skipped_uploads (Path, Size, ModTime) values (?, ?, ?);`,
+		localPath, fi.Size(), fi.ModTime().UnixNano())
+	return false, err
+}
+
+// uploadSkipped reports whether the upload of the local file at localPath was
+// skipped and the file has not changed since.
+func (c *syncCache) uploadSkipped(localPath string, fi os.FileInfo) (bool, error) {
+	var size, modTime int64
+	err := c.db.QueryRow(`select Size, ModTime from skipped_uploads where Path = ?;`, localPath).Scan(&size, &modTime)
+	if err == sql.ErrNoRows {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return size == fi.Size() && modTime == fi.ModTime().UnixNano(), nil
+}
+
+// resumableUploadSession returns the recorded upload session for localPath
+// along with the parts Box has already received, or starts a new session if
+// there is none or the local file has changed since it was started. A
+// recorded session keeps the ETag it was started with, as etag may have been
+// refreshed from Box since.
+func (c *syncCache) resumableUploadSession(localPath, parentID, fileID, etag string, fi os.FileInfo) (*UploadSessionEntry, []box.UploadPart, error) {
+	entry, err := c.getUploadSession(localPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if entry != nil {
+		if entry.Size == fi.Size() && entry.ModTime == fi.ModTime().UnixNano() &&
+			entry.FileID == fileID && (fileID != "" || entry.ParentID == parentID) {
+			parts, err := c.client.ListUploadParts(entry.SessionID)
+			if err == nil {
+				log.Printf("Resuming upload of %s (%d parts already uploaded)", localPath, len(parts))
+				return entry, parts, c.replaceUploadParts(entry.SessionID, parts)
+			} else if !box.IsNotFound(err) {
+				// The session is kept for the next attempt unless it
+				// expired.
+				return nil, nil, err
+			}
+			log.Printf("Upload session for %s can no longer be resumed: %v", localPath, err)
+		} else {
+			log.Printf("%s changed since its upload started, restarting upload", localPath)
+		}
+
+		c.client.AbortUploadSession(entry.SessionID)
+		err = c.deleteUploadSession(entry.SessionID)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var session *box.UploadSession
+	if fileID != "" {
+		session, err = c.client.CreateUploadSessionVersion(fileID, fi.Size())
+	} else {
+		session, err = c.client.CreateUploadSession(parentID, filepath.Base(localPath), fi.Size())
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	entry = &UploadSessionEntry{
+		Path:      localPath,
+		SessionID: session.ID,
+		FileID:    fileID,
+		ParentID:  parentID,
+		ETag:      etag,
+		SHA1:      sync.SHA1(localPath),
+		Size:      fi.Size(),
+		ModTime:   fi.ModTime().UnixNano(),
+		PartSize:  session.PartSize,
+	}
+
+	_, err = c.db.Exec(`insert into upload_sessions (Path, SessionID, FileID, ParentID, ETag, SHA1, Size, ModTime, PartSize) values (?, ?, ?, ?, ?, ?, ?, ?, ?);`,
+		entry.Path, entry.SessionID, entry.FileID, entry.ParentID, entry.ETag, entry.SHA1, entry.Size, entry.ModTime, entry.PartSize)
+	if err != nil {
+		c.client.AbortUploadSession(session.ID)
+		return nil, nil, err
+	}
+
+	return entry, nil, nil
+}
+
+// uploadMissingParts uploads every part of the session's file that is not in
+// uploaded, recording each one as it completes, and then commits the session.
+func (c *syncCache) uploadMissingParts(entry *UploadSessionEntry, uploaded []box.UploadPart) (*box.File, error) {
+	file, err := os.Open(entry.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	done := map[int64]box.UploadPart{}
+	for _, part := range uploaded {
+		done[part.Offset] = part
+	}
+
+	buf := make([]byte, entry.PartSize)
+	for offset := int64(0); offset < entry.Size; offset += entry.PartSize {
+		n := entry.PartSize
+		if entry.Size-offset < n {
+			n = entry.Size - offset
+		}
+		if part, ok := done[offset]; ok && part.Size == n {
+			continue
+		}
+
+		if _, err := file.ReadAt(buf[:n], offset); err != nil && err != io.EOF {
+			return nil, err
+		}
+		part, err := c.client.UploadPart(entry.SessionID, buf[:n], offset, entry.Size)
+		if err != nil {
+			return nil, err
+		}
+		done[offset] = *part
+
+		_, err = c.db.Exec(`insert or replace into upload_parts (SessionID, PartID, Offset, Size, SHA1) values (?, ?, ?, ?, ?);`,
+			entry.SessionID, part.PartID, part.Offset, part.Size, part.SHA1)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	parts := make([]box.UploadPart, 0, len(done))
+	for _, part := range done {
+		parts = append(parts, part)
+	}
+	sort.Sort(partsByOffset(parts))
+
+	boxFile, err := c.client.IfMatch(entry.ETag).CommitUploadSession(entry.SessionID, entry.SHA1, parts)
+	if isRejectedUpload(err) {
+		c.client.AbortUploadSession(entry.SessionID)
+		c.deleteUploadSession(entry.SessionID)
+		return nil, err
+	} else if err != nil {
+		// The parts stay uploaded, so the commit is tried again by the
+		// next attempt.
+		return nil, err
+	}
+
+	return boxFile, nil
+}
+
+// isRejectedUpload reports whether err is Box's definitive refusal of an
+// upload, as opposed to a failure that may go away if the upload is retried.
+func isRejectedUpload(err error) bool {
+	apiErr, ok := err.(*box.APIError)
+	return ok && apiErr.StatusCode >= 400 && apiErr.StatusCode < 500 && !box.IsRateLimited(err)
+}
+
+func (c *syncCache) getUploadSession(localPath string) (*UploadSessionEntry, error) {
+	row := c.db.QueryRow(`select Path, SessionID, FileID, ParentID, coalesce(ETag, ''), SHA1, Size, ModTime, PartSize from upload_sessions where Path = ?;`, localPath)
+
+	var entry UploadSessionEntry
+	err := row.Scan(&entry.Path, &entry.SessionID, &entry.FileID, &entry.ParentID, &entry.ETag, &entry.SHA1,
+		&entry.Size, &entry.ModTime, &entry.PartSize)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+func (c *syncCache) getUploadSessions() ([]UploadSessionEntry, error) {
+	rows, err := c.db.Query(`select Path, SessionID, FileID, ParentID, coalesce(ETag, ''), SHA1, Size, ModTime, PartSize from upload_sessions;`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []UploadSessionEntry
+	for rows.Next() {
+		var entry UploadSessionEntry
+		err := rows.Scan(&entry.Path, &entry.SessionID, &entry.FileID, &entry.ParentID, &entry.ETag, &entry.SHA1,
+			&entry.Size, &entry.ModTime, &entry.PartSize)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+func (c *syncCache) hasPendingUpload(localPath string) bool {
+	entry, err := c.getUploadSession(localPath)
+	return err == nil && entry != nil
+}
+
+func (c *syncCache) replaceUploadParts(sessionID string, parts []box.UploadPart) error {
+	tx, err := c.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`delete from upload_parts where SessionID = ?;`, sessionID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for _, part := range parts {
+		_, err = tx.Exec(`insert or replace into upload_parts (SessionID, PartID, Offset, Size, SHA1) values (?, ?, ?, ?, ?);`,
+			sessionID, part.PartID, part.Offset, part.Size, part.SHA1)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// discardUploadSession aborts and forgets the upload session recorded for
+// localPath, if any, e.g. once the file shrank below the chunked upload
+// threshold and is uploaded in one request instead.
+func (c *syncCache) discardUploadSession(localPath string) error {
+	entry, err := c.getUploadSession(localPath)
+	if err != nil || entry == nil {
+		return err
+	}
+	log.Printf("Abandoning the chunked upload of %s", localPath)
+	c.client.AbortUploadSession(entry.SessionID)
+	return c.deleteUploadSession(entry.SessionID)
+}
+
+func (c *syncCache) deleteUploadSession(sessionID string) error {
+	_, err := c.db.Exec(`delete from upload_parts where SessionID = ?;`, sessionID)
+	if err != nil {
+		return err
+	}
+	_, err = c.db.Exec(`delete from upload_sessions where SessionID = ?;`, sessionID)
+	return err
+}
+
+// resumeUploads finishes the uploads that were interrupted the last time the
+// cache was running. An upload that fails again is logged and left to be
+// resumed by the next start.
+func (c *syncCache) resumeUploads() error {
+	entries, err := c.getUploadSessions()
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if _, err := os.Stat(entry.Path); os.IsNotExist(err) {
+			log.Printf("%s no longer exists, aborting its upload", entry.Path)
+			c.client.AbortUploadSession(entry.SessionID)
+			if err := c.deleteUploadSession(entry.SessionID); err != nil {
+				log.Printf("Failed to forget the upload of %s: %v", entry.Path, err)
+			}
+			continue
+		}
+
+		if err := c.resumeUpload(entry); err != nil {
+			log.Printf("Failed to resume the upload of %s: %v", entry.Path, err)
+		}
+	}
+
+	return nil
+}
+
+// resumeUpload finishes the interrupted upload entry. A new version is only
+// committed if the remote file was not changed while the cache was stopped,
+// and is otherwise resolved as a conflict.
+func (c *syncCache) resumeUpload(entry UploadSessionEntry) error {
+	if entry.FileID == "" {
+		_, err := c.AddFileToDB(entry.Path)
+		return err
+	}
+
+	var remotePath, parentID string
+	err := c.db.QueryRow(`select Path, coalesce(ParentID, '') from files where ID = ?;`, entry.FileID).Scan(&remotePath, &parentID)
+	if err == sql.ErrNoRows {
+		// The file is gone remotely, and the next scan uploads the
+		// local copy as a new file.
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	file, err := c.uploadFile(entry.Path, "", entry.FileID, entry.ETag)
+	if box.IsPreconditionFailed(err) {
+		return c.resolveConflict(entry.Path, remotePath, entry.FileID, parentID)
+	} else if err != nil || file == nil {
+		return err
+	}
+	return c.updateUploadedFile(entry.Path, file)
+}
+
+// updateUploadedFile records the new version of the file at localPath that
+// was just uploaded.
+func (c *syncCache) updateUploadedFile(localPath string, file *box.File) error {
+	relPath, err := filepath.Rel(c.localRootDirectory, localPath)
+	if err != nil {
+		relPath = localPath
+	}
+	remotePath := filepath.Join(filepath.Base(c.remoteRootDirectory), relPath)
+
+	_, err = c.db.Exec(`update files set SHA1 = ?, SequenceID = ?, ETag = ?, Valid = ? where Path = ?;`,
+		file.SHA1, file.SequenceID, file.ETag, true, remotePath)
+	return err
+}
+
+type partsByOffset []box.UploadPart
+
+func (p partsByOffset) Len() int           { return len(p) }
+func (p partsByOffset) Less(i, j int) bool { return p[i].Offset < p[j].Offset }
+func (p partsByOffset) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
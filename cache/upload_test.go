@@ -0,0 +1,269 @@
+package cache
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gitlab.engr.illinois.edu/sp-box/boxsync/box"
+	"gitlab.engr.illinois.edu/sp-box/boxsync/sync"
+)
+
+func TestResumeUploadsContinuesAfterFailure(t *testing.T) {
+	var committed, aborted []string
+	client := &fakeClient{
+		threshold: 10,
+		listUploadParts: func(sessionID string) ([]box.UploadPart, error) {
+			return []box.UploadPart{{PartID: "p", Offset: 0, Size: 20}}, nil
+		},
+		commitUploadSession: func(sessionID, ifMatch string) (*box.File, error) {
+			committed = append(committed, sessionID)
+			switch sessionID {
+			case "s1":
+				return nil, &box.APIError{StatusCode: http.StatusBadGateway}
+			case "s3":
+				return nil, &box.APIError{StatusCode: http.StatusBadRequest, Code: "bad_digest"}
+			}
+			return &box.File{ID: "6", SHA1: "new", SequenceID: "2"}, nil
+		},
+		abortUploadSession: func(sessionID string) error {
+			aborted = append(aborted, sessionID)
+			return nil
+		},
+	}
+	c, cleanup := newTestCache(t, client)
+	defer cleanup()
+
+	for i, name := range []string{"a.bin", "b.bin", "c.bin"} {
+		localPath := filepath.Join(c.localRootDirectory, name)
+		err := ioutil.WriteFile(localPath, make([]byte, 20), 0644)
+		assert.NoError(t, err, "Function should not return error")
+		info, err := os.Stat(localPath)
+		assert.NoError(t, err, "Function should not return error")
+		fileID, sessionID := strconv.Itoa(5+i), "s"+strconv.Itoa(1+i)
+		_, err = c.db.Exec(`insert into files (Path, ID, SHA1) values (?, ?, 'old');
+		insert into upload_sessions (Path, SessionID, FileID, ParentID, SHA1, Size, ModTime, PartSize) values (?, ?, ?, '', '', 20, ?, 20);`,
+			"Box Sync/"+name, fileID, localPath, sessionID, fileID, info.ModTime().UnixNano())
+		assert.NoError(t, err, "Function should not return error")
+	}
+
+	err := c.resumeUploads()
+	assert.NoError(t, err, "Failed uploads should not stop the other uploads from resuming")
+	assert.Equal(t, []string{"s1", "s2", "s3"}, committed, "Every upload should be resumed")
+	assert.Equal(t, []string{"s3"}, aborted, "Only the upload Box rejected should be aborted")
+
+	var sessions []string
+	rows, err := c.db.Query(`select SessionID from upload_sessions;`)
+	assert.NoError(t, err, "Function should not return error")
+	for rows.Next() {
+		var sessionID string
+		rows.Scan(&sessionID)
+		sessions = append(sessions, sessionID)
+	}
+	rows.Close()
+	assert.Equal(t, []string{"s1"}, sessions, "Upload that failed transiently should be kept for the next start")
+
+	var sha1 string
+	err = c.db.QueryRow(`select SHA1 from files where ID = '6';`).Scan(&sha1)
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, "new", sha1, "Resumed upload should be recorded")
+}
+
+func TestRescanSkipsRejectedUploads(t *testing.T) {
+	var preflights, uploaded []string
+	client := &fakeClient{
+		getFile: func(id string) (*box.File, error) {
+			return &box.File{ID: id, SHA1: fmt.Sprintf("%x", sha1.Sum([]byte("remote "+id))), ETag: "1"}, nil
+		},
+		downloadFile: func(id, destPath string) error {
+			return ioutil.WriteFile(destPath, []byte("remote "+id), 0644)
+		},
+		preflightUpload: func(name, parentID string) error {
+			preflights = append(preflights, name)
+			switch name {
+			case "big.bin":
+				return &box.APIError{StatusCode: http.StatusForbidden, Code: box.ErrorCodeStorageLimitExceeded}
+			case "taken.txt":
+				return &box.APIError{
+					StatusCode:  http.StatusConflict,
+					Code:        box.ErrorCodeItemNameInUse,
+					ContextInfo: []byte(`{"conflicts": {"type": "file", "id": "9"}}`),
+				}
+			}
+			return nil
+		},
+		uploadFile: func(srcPath, parentID string) (*box.File, error) {
+			uploaded = append(uploaded, filepath.Base(srcPath))
+			return &box.File{ID: strconv.Itoa(20 + len(uploaded)), SHA1: sync.SHA1(srcPath)}, nil
+		},
+	}
+	c, cleanup := newTestCache(t, client)
+	defer cleanup()
+
+	bigPath := writeLocalFile(t, c, "big.bin", "too big")
+	takenPath := writeLocalFile(t, c, "taken.txt", "local")
+
+	for i := 0; i < 2; i++ {
+		err := c.rescanLocalTree()
+		assert.NoError(t, err, "Skipped upload should not fail the scan")
+	}
+	assert.Equal(t, 1, countOf(preflights, "big.bin"), "Skipped upload should not be checked again while unchanged")
+	assert.Equal(t, 1, countOf(preflights, "taken.txt"), "Name conflict should be resolved")
+	assert.Equal(t, 0, countOf(uploaded, "big.bin"), "File over the quota should not be uploaded")
+	assert.Equal(t, 1, len(uploaded), "Conflicted copy should be uploaded")
+
+	content, err := ioutil.ReadFile(takenPath)
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, "remote 9", string(content), "Remote file should replace the conflicting local one")
+	var id string
+	err = c.db.QueryRow(`select ID from files where Path = 'Box Sync/taken.txt';`).Scan(&id)
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, "9", id, "Conflicting remote file should be cached")
+
+	err = ioutil.WriteFile(bigPath, []byte("still too big"), 0644)
+	assert.NoError(t, err, "Function should not return error")
+	err = c.rescanLocalTree()
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, 2, countOf(preflights, "big.bin"), "Changed file should be checked again")
+}
+
+func countOf(names []string, name string) int {
+	n := 0
+	for _, s := range names {
+		if s == name {
+			n++
+		}
+	}
+	return n
+}
+
+func TestResumeUploadKeepsSessionOnListFailure(t *testing.T) {
+	var aborted []string
+	listErr := error(&box.APIError{StatusCode: http.StatusBadGateway})
+	client := &fakeClient{
+		threshold: 10,
+		listUploadParts: func(sessionID string) ([]box.UploadPart, error) {
+			return nil, listErr
+		},
+		abortUploadSession: func(sessionID string) error {
+			aborted = append(aborted, sessionID)
+			return nil
+		},
+	}
+	c, cleanup := newTestCache(t, client)
+	defer cleanup()
+
+	localPath := writeLocalFile(t, c, "a.bin", strings.Repeat("a", 20))
+	info, err := os.Stat(localPath)
+	assert.NoError(t, err, "Function should not return error")
+	_, err = c.db.Exec(`insert into files (Path, ID, SHA1, ParentID) values ('Box Sync/a.bin', '5', 'old', '0');
+	insert into upload_sessions (Path, SessionID, FileID, ParentID, SHA1, Size, ModTime, PartSize) values (?, 's1', '5', '', '', 20, ?, 20);`,
+		localPath, info.ModTime().UnixNano())
+	assert.NoError(t, err, "Function should not return error")
+
+	_, _, err = c.resumableUploadSession(localPath, "", "5", "1", info)
+	assert.Error(t, err, "Transient failure should be returned")
+	assert.Empty(t, aborted, "Session should not be aborted after a transient failure")
+	assert.True(t, c.hasPendingUpload(localPath), "Session should be kept after a transient failure")
+
+	listErr = &box.APIError{StatusCode: http.StatusNotFound, Code: box.ErrorCodeNotFound}
+	client.createUploadSessionVersion = func(fileID string) (*box.UploadSession, error) {
+		return &box.UploadSession{ID: "s2", PartSize: 20}, nil
+	}
+	entry, _, err := c.resumableUploadSession(localPath, "", "5", "1", info)
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, []string{"s1"}, aborted, "Expired session should be aborted")
+	assert.Equal(t, "s2", entry.SessionID, "Expired session should be replaced")
+}
+
+func TestSmallUploadDiscardsSession(t *testing.T) {
+	var aborted, versions []string
+	client := &fakeClient{
+		threshold: 10,
+		uploadFileVersion: func(fileID, srcPath, ifMatch string) (*box.File, error) {
+			versions = append(versions, fileID)
+			return &box.File{ID: fileID, SHA1: "new", ETag: "2"}, nil
+		},
+		abortUploadSession: func(sessionID string) error {
+			aborted = append(aborted, sessionID)
+			return nil
+		},
+	}
+	c, cleanup := newTestCache(t, client)
+	defer cleanup()
+
+	// The file was large when its upload started, and was then truncated.
+	localPath := writeLocalFile(t, c, "a.bin", "small")
+	_, err := c.db.Exec(`insert into upload_sessions (Path, SessionID, FileID, ParentID, SHA1, Size, ModTime, PartSize) values (?, 's1', '5', '', '', 20, 0, 20);`,
+		localPath)
+	assert.NoError(t, err, "Function should not return error")
+
+	_, err = c.uploadFile(localPath, "", "5", "1")
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, []string{"5"}, versions, "Small file should be uploaded in one request")
+	assert.Equal(t, []string{"s1"}, aborted, "Stale upload session should be aborted")
+	assert.False(t, c.hasPendingUpload(localPath), "Stale upload session should be forgotten")
+}
+
+func TestResumeUploadIsConditional(t *testing.T) {
+	var commits []string
+	client := &fakeClient{
+		threshold: 10,
+		getFile: func(id string) (*box.File, error) {
+			return &box.File{ID: id, SHA1: "remote", ETag: "2"}, nil
+		},
+		downloadFile: func(id, destPath string) error {
+			return ioutil.WriteFile(destPath, []byte("remote "+id), 0644)
+		},
+		createUploadSession: func(parentID, name string) (*box.UploadSession, error) {
+			return &box.UploadSession{ID: "s2", PartSize: 20}, nil
+		},
+		uploadPart: func(sessionID string, offset int64) (*box.UploadPart, error) {
+			return &box.UploadPart{PartID: "q", Offset: offset, Size: 20}, nil
+		},
+		listUploadParts: func(sessionID string) ([]box.UploadPart, error) {
+			return []box.UploadPart{{PartID: "p", Offset: 0, Size: 20}}, nil
+		},
+		commitUploadSession: func(sessionID, ifMatch string) (*box.File, error) {
+			commits = append(commits, sessionID+"@"+ifMatch)
+			if sessionID == "s2" {
+				return &box.File{ID: "9", SHA1: "copy"}, nil
+			}
+			return nil, &box.APIError{StatusCode: http.StatusPreconditionFailed, Code: box.ErrorCodePreconditionFailed}
+		},
+		abortUploadSession: func(sessionID string) error {
+			return nil
+		},
+	}
+	c, cleanup := newTestCache(t, client)
+	defer cleanup()
+
+	// The refresh at startup already cached the version edited on Box
+	// while the cache was stopped.
+	localPath := writeLocalFile(t, c, "a.bin", strings.Repeat("a", 20))
+	info, err := os.Stat(localPath)
+	assert.NoError(t, err, "Function should not return error")
+	_, err = c.db.Exec(`insert into files (Path, ID, SHA1, ETag, ParentID) values ('Box Sync/a.bin', '5', 'remote', '2', '0');
+	insert into upload_sessions (Path, SessionID, FileID, ParentID, ETag, SHA1, Size, ModTime, PartSize) values (?, 's1', '5', '', '1', '', 20, ?, 20);`,
+		localPath, info.ModTime().UnixNano())
+	assert.NoError(t, err, "Function should not return error")
+
+	err = c.resumeUploads()
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, []string{"s1@1", "s2@"}, commits, "Resumed upload should be conditional on the version it started from")
+
+	content, err := ioutil.ReadFile(localPath)
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, "remote 5", string(content), "Remote edit should be kept")
+	copies, err := filepath.Glob(filepath.Join(c.localRootDirectory, "a (conflicted copy *).bin"))
+	assert.NoError(t, err, "Function should not return error")
+	assert.Len(t, copies, 1, "Local changes should be kept in a conflicted copy")
+}
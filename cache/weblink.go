@@ -0,0 +1,204 @@
+package cache
+
+import (
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"gitlab.engr.illinois.edu/sp-box/boxsync/box"
+)
+
+// shortcutExt is the extension of the local files standing for web links.
+var shortcutExt = shortcutExtension(runtime.GOOS)
+
+// shortcutExtension returns the extension of the shortcut files understood by
+// the desktop of goos: Internet shortcuts on Windows and macOS, and desktop
+// entries elsewhere.
+func shortcutExtension(goos string) string {
+	switch goos {
+	case "windows", "darwin":
+		return ".url"
+	}
+	return ".desktop"
+}
+
+// shortcutContent returns the content of a shortcut file with the extension
+// ext to url, titled name.
+func shortcutContent(ext, name, url string) []byte {
+	if ext == ".url" {
+		return []byte("[InternetShortcut]\r\nURL=" + url + "\r\n")
+	}
+	return []byte("[Desktop Entry]\nType=Link\nName=" + name + "\nURL=" + url + "\n")
+}
+
+// parseShortcut returns the URL in the content of a shortcut file, or an
+// empty string if there is none.
+func parseShortcut(content []byte) string {
+	for _, line := range strings.Split(string(content), "\n") {
+		i := strings.Index(line, "=")
+		if i < 0 {
+			continue
+		}
+		switch strings.TrimSpace(line[:i]) {
+		case "URL", "URL[$e]":
+			return strings.TrimSpace(line[i+1:])
+		}
+	}
+	return ""
+}
+
+// shortcutURL returns the URL of the local shortcut at localPath, or an empty
+// string if it cannot be read.
+func shortcutURL(localPath string) string {
+	content, err := ioutil.ReadFile(localPath)
+	if err != nil {
+		return ""
+	}
+	return parseShortcut(content)
+}
+
+// cacheWebLink records webLink, which is in the folder parentID, at
+// remotePath and writes its local shortcut unless it already points to the
+// same URL.
+func (c *syncCache) cacheWebLink(webLink box.WebLink, parentID, remotePath string) error {
+	localPath := c.localPath(remotePath)
+	if shortcutURL(localPath) != webLink.URL {
+		err := ioutil.WriteFile(localPath, shortcutContent(shortcutExt, webLink.Name, webLink.URL), 0644)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := c.db.Exec(`insert or replace into weblinks (Path, ID, URL, SequenceID, ParentID, Inode) values (?, ?, ?, ?, ?, ?);`,
+		remotePath, webLink.ID, webLink.URL, webLink.SequenceID, parentID, localInode(localPath))
+	return err
+}
+
+// syncLocalWebLinks updates the web links whose local shortcuts were edited,
+// moved or renamed. It returns the local files that are not web links, to be
+// synced as files, and the cached web links that no longer exist locally,
+// keyed by path. New shortcuts are created as web links, unless they hold no
+// URL.
+func (c *syncCache) syncLocalWebLinks(files []localItem) ([]localItem, map[string]cachedItem, error) {
+	local := map[string]bool{}
+	for _, file := range files {
+		local[file.remotePath] = true
+	}
+
+	cached, err := c.cachedItems(`select Path, coalesce(ID, ''), coalesce(URL, ''), coalesce(Inode, 0), '' from weblinks;`)
+	if err != nil {
+		return nil, nil, err
+	}
+	missing := missingItems(cached, local)
+
+	// Box files that merely share the shortcut extension are synced as
+	// files.
+	cachedFiles, err := c.cachedItems(`select Path, coalesce(ID, ''), coalesce(SHA1, ''), coalesce(Inode, 0), '' from files where Path like ?;`, "%"+shortcutExt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var others []localItem
+	for _, file := range files {
+		if err := c.ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		if _, ok := cachedFiles[file.remotePath]; ok || filepath.Ext(file.localPath) != shortcutExt {
+			others = append(others, file)
+			continue
+		}
+
+		url := shortcutURL(file.localPath)
+		if item, ok := cached[file.remotePath]; ok {
+			if url != "" && url != item.SHA1 {
+				err := c.updateWebLinkURL(file.remotePath, item.ID, url)
+				if err != nil {
+					log.Printf("Failed to update web link %s: %v", file.localPath, err)
+				}
+			}
+			continue
+		}
+
+		oldPath := findMovedItem(missing, file.inode, url, filepath.Base(file.remotePath))
+		if oldPath == "" && url == "" {
+			others = append(others, file)
+			continue
+		} else if oldPath == "" {
+			// A shortcut that cannot be created is tried again by the
+			// next scan.
+			err := c.createWebLink(file, url)
+			if err != nil {
+				log.Printf("Failed to create web link %s: %v", file.localPath, err)
+			}
+			continue
+		}
+		item := missing[oldPath]
+		err := c.moveWebLink(oldPath, item, file)
+		if err != nil {
+			log.Printf("Failed to move web link %s to %s: %v", oldPath, file.remotePath, err)
+			others = append(others, file)
+			continue
+		}
+		delete(missing, oldPath)
+		if url != "" && url != item.SHA1 {
+			err := c.updateWebLinkURL(file.remotePath, item.ID, url)
+			if err != nil {
+				log.Printf("Failed to update web link %s: %v", file.localPath, err)
+			}
+		}
+	}
+
+	return others, missing, nil
+}
+
+// createWebLink creates a web link to url from the new shortcut file.
+func (c *syncCache) createWebLink(file localItem, url string) error {
+	parentID, err := c.addFolderToDB(filepath.Dir(file.remotePath))
+	if err != nil {
+		return err
+	}
+
+	name := strings.TrimSuffix(filepath.Base(file.remotePath), shortcutExt)
+	webLink, err := c.client.CreateWebLink(url, parentID, name, "")
+	if err != nil {
+		return err
+	}
+	log.Printf("Created web link %s to %s", file.remotePath, url)
+
+	_, err = c.db.Exec(`insert into weblinks (Path, ID, URL, SequenceID, ParentID, Inode) values (?, ?, ?, ?, ?, ?);`,
+		file.remotePath, webLink.ID, webLink.URL, webLink.SequenceID, parentID, file.inode)
+	return err
+}
+
+// moveWebLink moves the cached web link at oldPath to where the shortcut file
+// is now, both remotely and in the cache.
+func (c *syncCache) moveWebLink(oldPath string, item cachedItem, file localItem) error {
+	parentID, name, err := c.moveTarget(oldPath, file.remotePath)
+	if err != nil {
+		return err
+	}
+
+	moved, err := c.client.MoveWebLink(item.ID, parentID, strings.TrimSuffix(name, shortcutExt))
+	if err != nil {
+		return err
+	}
+	log.Printf("Moved web link %s to %s", oldPath, file.remotePath)
+
+	_, err = c.db.Exec(`update weblinks set Path = ?, SequenceID = ?, ParentID = ?, Inode = ? where Path = ?;`,
+		file.remotePath, moved.SequenceID, parentID, file.inode, oldPath)
+	return err
+}
+
+// updateWebLinkURL points the web link id, cached at remotePath, to url.
+func (c *syncCache) updateWebLinkURL(remotePath, id, url string) error {
+	webLink, err := c.client.UpdateWebLink(id, box.WebLinkUpdate{URL: url})
+	if err != nil {
+		return err
+	}
+	log.Printf("Updated web link %s to %s", remotePath, url)
+
+	_, err = c.db.Exec(`update weblinks set URL = ?, SequenceID = ? where Path = ?;`, webLink.URL, webLink.SequenceID, remotePath)
+	return err
+}
@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gitlab.engr.illinois.edu/sp-box/boxsync/box"
+)
+
+func TestShortcutRoundTrip(t *testing.T) {
+	assert.Equal(t, ".url", shortcutExtension("windows"), "Windows should use Internet shortcuts")
+	assert.Equal(t, ".url", shortcutExtension("darwin"), "macOS should use Internet shortcuts")
+	assert.Equal(t, ".desktop", shortcutExtension("linux"), "Linux should use desktop entries")
+
+	for _, ext := range []string{".url", ".desktop"} {
+		content := shortcutContent(ext, "Lab wiki", "https://wiki.example.com/a?b=c")
+		assert.Equal(t, "https://wiki.example.com/a?b=c", parseShortcut(content), "URL should round-trip through %s", ext)
+	}
+	assert.Equal(t, "https://example.com", parseShortcut([]byte("[Desktop Entry]\nName=x\nURL[$e]=https://example.com\n")),
+		"Expandable URL key should be read")
+	assert.Equal(t, "", parseShortcut([]byte("just some text")), "Content without a URL should not parse")
+}
+
+func TestSyncLocalWebLinks(t *testing.T) {
+	var created []string
+	client := &fakeClient{
+		createWebLink: func(url, parentID, name string) (*box.WebLink, error) {
+			created = append(created, name+" "+url+" in "+parentID)
+			return &box.WebLink{ID: "3", URL: url, SequenceID: "0"}, nil
+		},
+	}
+	c, cleanup := newTestCache(t, client)
+	defer cleanup()
+
+	for name, id := range map[string]string{"wiki" + shortcutExt: "1", "gone" + shortcutExt: "2"} {
+		_, err := c.db.Exec(`insert into weblinks (Path, ID, URL, Inode) values (?, ?, ?, ?);`,
+			filepath.Join("Box Sync", name), id, "https://"+id+".example.com", 100+len(id))
+		assert.NoError(t, err, "Function should not return error")
+	}
+	_, err := c.db.Exec(`insert into files (Path, ID, SHA1) values (?, '4', 'synced');`, filepath.Join("Box Sync", "synced"+shortcutExt))
+	assert.NoError(t, err, "Function should not return error")
+
+	var files []localItem
+	for name, content := range map[string]string{
+		"wiki" + shortcutExt:   string(shortcutContent(shortcutExt, "wiki", "https://1.example.com")),
+		"new" + shortcutExt:    string(shortcutContent(shortcutExt, "new", "https://3.example.com")),
+		"empty" + shortcutExt:  "no link here",
+		"synced" + shortcutExt: string(shortcutContent(shortcutExt, "synced", "https://4.example.com")),
+		"notes.txt":            "notes",
+	} {
+		localPath := writeLocalFile(t, c, name, content)
+		files = append(files, localItem{localPath: localPath, remotePath: c.remotePath(localPath), inode: localInode(localPath)})
+	}
+
+	others, missing, err := c.syncLocalWebLinks(files)
+	assert.NoError(t, err, "Function should not return error")
+	var otherNames []string
+	for _, file := range others {
+		otherNames = append(otherNames, filepath.Base(file.localPath))
+	}
+	sort.Strings(otherNames)
+	assert.Equal(t, []string{"empty" + shortcutExt, "notes.txt", "synced" + shortcutExt}, otherNames, "Shortcuts without a URL, cached files and other files should be synced as files")
+	assert.Len(t, missing, 1, "Deleted shortcut should be reported")
+	assert.Equal(t, "2", missing[filepath.Join("Box Sync", "gone"+shortcutExt)].ID, "Deleted shortcut should be reported by path")
+
+	assert.Equal(t, []string{"new https://3.example.com in 0"}, created, "New shortcut should be created as a web link")
+	var id string
+	err = c.db.QueryRow(`select ID from weblinks where Path = ?;`, filepath.Join("Box Sync", "new"+shortcutExt)).Scan(&id)
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, "3", id, "Created web link should be cached")
+}
@@ -1,17 +1,28 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/urfave/cli"
+	"golang.org/x/net/context"
 
 	"gitlab.engr.illinois.edu/sp-box/boxsync/auth"
 	"gitlab.engr.illinois.edu/sp-box/boxsync/box"
 	"gitlab.engr.illinois.edu/sp-box/boxsync/sync"
 )
 
+const timeFormat = "2006-01-02 15:04:05"
+
 func main() {
 
 	httpClient, err := auth.Login()
@@ -19,9 +30,31 @@ func main() {
 		log.Fatal(err)
 	}
 
-	client := box.NewClient(httpClient)
+	// Interrupting a command cancels its requests instead of leaving them
+	// running.
+	ctx, cancel := context.WithCancel(context.Background())
+	interruptC := make(chan os.Signal, 1)
+	signal.Notify(interruptC, os.Interrupt)
+	go func() {
+		<-interruptC
+		cancel()
+	}()
+
+	client := box.NewClient(httpClient).WithContext(ctx)
 
 	app := cli.NewApp()
+	app.Flags = []cli.Flag{
+		cli.StringFlag{
+			Name:  "as-user",
+			Usage: "act on behalf of the managed user with this id (admins only)",
+		},
+	}
+	app.Before = func(c *cli.Context) error {
+		if userID := c.GlobalString("as-user"); userID != "" {
+			client = client.AsUser(userID)
+		}
+		return nil
+	}
 	app.Commands = []cli.Command{
 		{
 			Name:    "getCurrUser",
@@ -68,16 +101,32 @@ func main() {
 		{
 			Name:    "upload",
 			Aliases: []string{"up"},
-			Usage:   "Upload file",
+			Usage:   "Upload file, or standard input if the filename is -",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "name",
+					Usage: "name of the uploaded file when reading from standard input",
+				},
+			},
 			Action: func(c *cli.Context) error {
-				if len(os.Args) < 3 {
+				if c.NArg() < 1 {
 					log.Fatal("Specify filename for upload")
 				}
 				parentId := "0"
-				if len(os.Args) > 3 {
-					parentId = os.Args[3]
+				if c.NArg() > 1 {
+					parentId = c.Args().Get(1)
+				}
+
+				var file *box.File
+				var err error
+				if c.Args().First() == "-" {
+					if c.String("name") == "" {
+						log.Fatal("Specify --name when uploading from standard input")
+					}
+					file, err = client.UploadReader(os.Stdin, c.String("name"), parentId, -1)
+				} else {
+					file, err = client.UploadFile(c.Args().First(), parentId)
 				}
-				file, err := client.UploadFile(os.Args[2], parentId)
 
 				if err != nil {
 					log.Fatal(err)
@@ -106,6 +155,172 @@ func main() {
 				return nil
 			},
 		},
+		{
+			Name:  "cat",
+			Usage: "Write file content to standard output",
+			Flags: []cli.Flag{
+				cli.Int64Flag{
+					Name:  "offset",
+					Usage: "byte offset to start reading at",
+				},
+				cli.Int64Flag{
+					Name:  "length",
+					Value: -1,
+					Usage: "number of bytes to read, or -1 to read to the end of the file",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				if c.NArg() < 1 {
+					log.Fatal("Specify file id")
+				}
+				_, err := client.DownloadFileRange(c.Args().First(), os.Stdout, c.Int64("offset"), c.Int64("length"))
+				if err != nil && err != io.EOF {
+					log.Fatal(err)
+				}
+				return nil
+			},
+		},
+		{
+			Name:  "thumb",
+			Usage: "Save a thumbnail image of a file",
+			Flags: []cli.Flag{
+				cli.IntFlag{
+					Name:  "size",
+					Value: 320,
+					Usage: "minimum width & height of the thumbnail in pixels: 32, 94, 160 or 320, or 1024 or 2048 for jpg",
+				},
+				cli.StringFlag{
+					Name:  "format",
+					Value: box.ThumbnailPNG,
+					Usage: "image format of the thumbnail, png or jpg",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				if c.NArg() < 1 {
+					log.Fatal("Specify file path")
+				}
+				file, err := sync.ResolveFilePath(client, c.Args().First())
+				if err != nil {
+					log.Fatal(err)
+				}
+				destPath := c.Args().Get(1)
+				if destPath == "" {
+					destPath = strings.TrimSuffix(file.Name, filepath.Ext(file.Name)) + "." + c.String("format")
+				}
+
+				out, err := os.Create(destPath)
+				if err != nil {
+					log.Fatal(err)
+				}
+				opts := &box.ThumbnailOptions{MinWidth: c.Int("size"), MinHeight: c.Int("size")}
+				err = client.GetThumbnail(file.ID, c.String("format"), opts, out)
+				if closeErr := out.Close(); err == nil {
+					err = closeErr
+				}
+				if err != nil {
+					os.Remove(destPath)
+					log.Fatal(err)
+				}
+				fmt.Println("Saved thumbnail to " + destPath)
+				return nil
+			},
+		},
+		{
+			Name:  "text",
+			Usage: "Write the text Box extracted from one or more documents to standard output",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "dir",
+					Usage: "write the text of each document to a .txt file in this directory instead",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				if c.NArg() < 1 {
+					log.Fatal("Specify file paths")
+				}
+				for _, filePath := range c.Args() {
+					file, err := sync.ResolveFilePath(client, filePath)
+					if err != nil {
+						log.Fatal(err)
+					}
+					if c.String("dir") == "" {
+						err = extractedText(client, file.ID, os.Stdout)
+						if err != nil {
+							log.Fatal(filePath + ": " + err.Error())
+						}
+						continue
+					}
+
+					destPath := filepath.Join(c.String("dir"), file.Name+".txt")
+					out, err := os.Create(destPath)
+					if err != nil {
+						log.Fatal(err)
+					}
+					err = extractedText(client, file.ID, out)
+					if closeErr := out.Close(); err == nil {
+						err = closeErr
+					}
+					if err != nil {
+						// Documents without text are skipped so that a
+						// whole folder can be indexed.
+						os.Remove(destPath)
+						log.Print(filePath + ": " + err.Error())
+						continue
+					}
+					fmt.Println("Saved text to " + destPath)
+				}
+				return nil
+			},
+		},
+		{
+			Name:  "versions",
+			Usage: "List the versions of a file, numbered from the oldest",
+			Action: func(c *cli.Context) error {
+				if c.NArg() < 1 {
+					log.Fatal("Specify file path")
+				}
+				file, versions := fileVersions(client, c.Args().First())
+
+				fmt.Println("Versions of " + file.Name + " " + file.ID + ":")
+				n := len(versions) + 1
+				fmt.Printf("%d current %s %d bytes %s\n", n, file.ModifiedAt.Format(timeFormat), file.Size, file.ModifiedBy.Name)
+				for i, version := range versions {
+					fmt.Printf("%d %s %s %d bytes %s\n", n-1-i, version.ID, version.ModifiedAt.Format(timeFormat), version.Size, version.ModifiedBy.Name)
+				}
+				return nil
+			},
+		},
+		{
+			Name:  "restore",
+			Usage: "Make an old version of a file the current one",
+			Flags: []cli.Flag{
+				cli.IntFlag{
+					Name:  "version",
+					Usage: "number of the version to restore, as listed by versions",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				if c.NArg() < 1 {
+					log.Fatal("Specify file path")
+				}
+				file, versions := fileVersions(client, c.Args().First())
+
+				n := c.Int("version")
+				switch {
+				case n == len(versions)+1:
+					log.Fatalf("Version %d is already the current version", n)
+				case n < 1 || n > len(versions):
+					log.Fatalf("Specify --version between 1 and %d", len(versions))
+				}
+
+				_, err := client.PromoteFileVersion(file.ID, versions[len(versions)-n].ID)
+				if err != nil {
+					log.Fatal(err)
+				}
+				fmt.Printf("Restored version %d of %s\n", n, file.Name)
+				return nil
+			},
+		},
 		{
 			Name:    "watchEvents",
 			Aliases: []string{"wE"},
@@ -130,9 +345,11 @@ func main() {
 						fmt.Println(event)
 					case err := <-errs:
 						log.Fatal(err)
+					case <-ctx.Done():
+						close(quit)
+						return nil
 					}
 				}
-				return nil
 			},
 		},
 		{
@@ -187,6 +404,1446 @@ func main() {
 				return nil
 			},
 		},
+		{
+			Name:  "mv",
+			Usage: "Move or rename file or folder",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "folder",
+					Usage: "the id is a folder id",
+				},
+				cli.StringFlag{
+					Name:  "name",
+					Usage: "new name of the item",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				if c.NArg() < 1 {
+					log.Fatal("Specify file or folder id")
+				}
+				parentId := c.Args().Get(1)
+				if parentId == "" && c.String("name") == "" {
+					log.Fatal("Specify parent folder id or --name")
+				}
+
+				var name, id string
+				var err error
+				if c.Bool("folder") {
+					var folder *box.Folder
+					folder, err = client.MoveFolder(c.Args().First(), parentId, c.String("name"))
+					if folder != nil {
+						name, id = folder.Name, folder.ID
+					}
+				} else {
+					var file *box.File
+					file, err = client.MoveFile(c.Args().First(), parentId, c.String("name"))
+					if file != nil {
+						name, id = file.Name, file.ID
+					}
+				}
+
+				if err != nil {
+					log.Fatal(err)
+				}
+				fmt.Println("Move successful")
+				fmt.Println("Name & ID: " + name + " " + id)
+				return nil
+			},
+		},
+		{
+			Name:  "cp",
+			Usage: "Copy file or folder",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "folder",
+					Usage: "the id is a folder id",
+				},
+				cli.StringFlag{
+					Name:  "name",
+					Usage: "name of the copy",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				if c.NArg() < 2 {
+					log.Fatal("Specify file or folder id & parent folder id")
+				}
+
+				var name, id string
+				var err error
+				if c.Bool("folder") {
+					var folder *box.Folder
+					folder, err = client.CopyFolder(c.Args().First(), c.Args().Get(1), c.String("name"))
+					if folder != nil {
+						name, id = folder.Name, folder.ID
+					}
+				} else {
+					var file *box.File
+					file, err = client.CopyFile(c.Args().First(), c.Args().Get(1), c.String("name"))
+					if file != nil {
+						name, id = file.Name, file.ID
+					}
+				}
+
+				if err != nil {
+					log.Fatal(err)
+				}
+				fmt.Println("Copy successful")
+				fmt.Println("Name & ID: " + name + " " + id)
+				return nil
+			},
+		},
+		{
+			Name:  "lock",
+			Usage: "Lock one or more files so that other users cannot edit them",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "expires",
+					Usage: "when the lock expires, as a date (2006-01-02), a time (RFC 3339) or a duration from now (72h, 7d)",
+				},
+				cli.BoolFlag{
+					Name:  "prevent-download",
+					Usage: "also prevent other users from downloading the files",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				if c.NArg() < 1 {
+					log.Fatal("Specify file paths")
+				}
+				var expires *time.Time
+				if c.String("expires") != "" {
+					t, err := parseExpiry(c.String("expires"), time.Now())
+					if err != nil {
+						log.Fatal(err)
+					}
+					expires = &t
+				}
+				for _, filePath := range c.Args() {
+					file, err := sync.ResolveFilePath(client, filePath)
+					if err != nil {
+						log.Fatal(err)
+					}
+					_, err = client.LockFile(file.ID, expires, c.Bool("prevent-download"))
+					if err != nil {
+						log.Fatal(err)
+					}
+					fmt.Println("Locked " + filePath)
+				}
+				return nil
+			},
+		},
+		{
+			Name:  "unlock",
+			Usage: "Unlock one or more files",
+			Action: func(c *cli.Context) error {
+				if c.NArg() < 1 {
+					log.Fatal("Specify file paths")
+				}
+				for _, filePath := range c.Args() {
+					file, err := sync.ResolveFilePath(client, filePath)
+					if err != nil {
+						log.Fatal(err)
+					}
+					err = client.UnlockFile(file.ID)
+					if err != nil {
+						log.Fatal(err)
+					}
+					fmt.Println("Unlocked " + filePath)
+				}
+				return nil
+			},
+		},
+		{
+			Name:  "share",
+			Usage: "Create or update the shared link of a file or folder & print its URL",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "access",
+					Usage: "who can use the link: open, company or collaborators",
+				},
+				cli.StringFlag{
+					Name:  "expires",
+					Usage: "when the link expires, as a date (2006-01-02), a time (RFC 3339) or a duration from now (72h, 7d)",
+				},
+				cli.StringFlag{
+					Name:  "password",
+					Usage: "password required to use the link",
+				},
+				cli.BoolFlag{
+					Name:  "no-password",
+					Usage: "remove the password of the link",
+				},
+				cli.BoolFlag{
+					Name:  "no-expiry",
+					Usage: "remove the expiry of the link",
+				},
+				cli.BoolFlag{
+					Name:  "no-download",
+					Usage: "only allow previewing the item",
+				},
+				cli.BoolFlag{
+					Name:  "download",
+					Usage: "allow downloading the item again",
+				},
+				cli.BoolFlag{
+					Name:  "remove",
+					Usage: "remove the shared link instead",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				if c.NArg() < 1 {
+					log.Fatal("Specify file or folder path")
+				}
+				file, folder, err := sync.ResolvePath(client, c.Args().First())
+				if err != nil {
+					log.Fatal(err)
+				}
+
+				if c.Bool("remove") {
+					if file != nil {
+						err = client.RemoveFileSharedLink(file.ID)
+					} else {
+						err = client.RemoveFolderSharedLink(folder.ID)
+					}
+					if err != nil {
+						log.Fatal(err)
+					}
+					fmt.Println("Shared link removed")
+					return nil
+				}
+
+				settings := &box.SharedLinkSettings{
+					Access:        c.String("access"),
+					Password:      c.String("password"),
+					ClearPassword: c.Bool("no-password"),
+					ClearExpiry:   c.Bool("no-expiry"),
+				}
+				if c.String("expires") != "" {
+					expires, err := parseExpiry(c.String("expires"), time.Now())
+					if err != nil {
+						log.Fatal(err)
+					}
+					settings.UnsharedAt = &expires
+				}
+				settings.Permissions, err = downloadPermission(c.Bool("download"), c.Bool("no-download"))
+				if err != nil {
+					log.Fatal(err)
+				}
+
+				var link *box.SharedLink
+				if file != nil {
+					link, err = client.SetFileSharedLink(file.ID, settings)
+				} else {
+					link, err = client.SetFolderSharedLink(folder.ID, settings)
+				}
+				if err != nil {
+					log.Fatal(err)
+				}
+				fmt.Println(link.URL)
+				return nil
+			},
+		},
+		{
+			Name:  "weblink",
+			Usage: "Create, change & delete web links",
+			Subcommands: []cli.Command{
+				{
+					Name:  "add",
+					Usage: "Create a web link to a URL in a folder",
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "name",
+							Usage: "name of the web link, the URL by default",
+						},
+						cli.StringFlag{
+							Name:  "description",
+							Usage: "description of the web link",
+						},
+					},
+					Action: func(c *cli.Context) error {
+						if c.NArg() < 2 {
+							log.Fatal("Specify URL & folder path")
+						}
+						folder, err := sync.ResolveFolderPath(client, c.Args().Get(1))
+						if err != nil {
+							log.Fatal(err)
+						}
+						webLink, err := client.CreateWebLink(c.Args().First(), folder.ID, c.String("name"), c.String("description"))
+						if err != nil {
+							log.Fatal(err)
+						}
+						fmt.Println("Created web link " + webLink.Name + " " + webLink.ID)
+						return nil
+					},
+				},
+				{
+					Name:  "set",
+					Usage: "Change the URL, name or description of a web link",
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "url",
+							Usage: "new URL of the web link",
+						},
+						cli.StringFlag{
+							Name:  "name",
+							Usage: "new name of the web link",
+						},
+						cli.StringFlag{
+							Name:  "description",
+							Usage: "new description of the web link",
+						},
+					},
+					Action: func(c *cli.Context) error {
+						if c.NArg() < 1 {
+							log.Fatal("Specify web link id")
+						}
+						webLink, err := client.UpdateWebLink(c.Args().First(), box.WebLinkUpdate{
+							URL:         c.String("url"),
+							Name:        c.String("name"),
+							Description: c.String("description"),
+						})
+						if err != nil {
+							log.Fatal(err)
+						}
+						fmt.Println(webLink.Name + " " + webLink.URL)
+						return nil
+					},
+				},
+				{
+					Name:  "rm",
+					Usage: "Delete a web link",
+					Action: func(c *cli.Context) error {
+						if c.NArg() < 1 {
+							log.Fatal("Specify web link id")
+						}
+						err := client.DeleteWebLink(c.Args().First())
+						if err != nil {
+							log.Fatal(err)
+						}
+						fmt.Println("Web link deleted")
+						return nil
+					},
+				},
+			},
+		},
+		{
+			Name:  "fav",
+			Usage: "List, add & remove favorites",
+			Subcommands: []cli.Command{
+				{
+					Name:  "ls",
+					Usage: "List the favorites",
+					Action: func(c *cli.Context) error {
+						favorites, err := client.GetFavorites()
+						if err != nil {
+							log.Fatal(err)
+						}
+						it := client.GetCollectionItems(favorites.ID, nil)
+						for it.Next() {
+							for _, fd := range it.Page().Folders {
+								fmt.Println("folder " + fd.Name + " " + fd.ID)
+							}
+							for _, fe := range it.Page().Files {
+								fmt.Println("file " + fe.Name + " " + fe.ID)
+							}
+							for _, wl := range it.Page().WebLinks {
+								fmt.Println("web_link " + wl.Name + " " + wl.ID + " " + wl.URL)
+							}
+						}
+						if err := it.Err(); err != nil {
+							log.Fatal(err)
+						}
+						return nil
+					},
+				},
+				{
+					Name:  "add",
+					Usage: "Add one or more files or folders to the favorites",
+					Action: func(c *cli.Context) error {
+						return updateFavorites(client, c, client.AddToCollection, "Added ")
+					},
+				},
+				{
+					Name:  "rm",
+					Usage: "Remove one or more files or folders from the favorites",
+					Action: func(c *cli.Context) error {
+						return updateFavorites(client, c, client.RemoveFromCollection, "Removed ")
+					},
+				},
+			},
+		},
+		{
+			Name:  "search",
+			Usage: "Search for files & folders",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "type",
+					Usage: "only return items of this type: file, folder or web_link",
+				},
+				cli.StringSliceFlag{
+					Name:  "ext",
+					Usage: "only return files with this extension, e.g. pdf; may be repeated",
+				},
+				cli.StringSliceFlag{
+					Name:  "ancestor",
+					Usage: "only return items inside the folder with this id; may be repeated",
+				},
+				cli.StringSliceFlag{
+					Name:  "content-type",
+					Usage: "only match name, description, file_content, comments or tags; may be repeated",
+				},
+				cli.StringFlag{
+					Name:  "created-after",
+					Usage: "only return items created at or after this date (2006-01-02) or time (RFC 3339)",
+				},
+				cli.StringFlag{
+					Name:  "created-before",
+					Usage: "only return items created at or before this date or time",
+				},
+				cli.StringFlag{
+					Name:  "updated-after",
+					Usage: "only return items updated at or after this date or time",
+				},
+				cli.StringFlag{
+					Name:  "updated-before",
+					Usage: "only return items updated at or before this date or time",
+				},
+				cli.Int64Flag{
+					Name:  "min-size",
+					Usage: "only return items of at least this many bytes",
+				},
+				cli.Int64Flag{
+					Name:  "max-size",
+					Usage: "only return items of at most this many bytes",
+				},
+				cli.StringSliceFlag{
+					Name:  "owner",
+					Usage: "only return items owned by the user with this id; may be repeated",
+				},
+				cli.IntFlag{
+					Name:  "limit",
+					Value: 100,
+					Usage: "maximum number of results",
+				},
+				cli.BoolFlag{
+					Name:  "json",
+					Usage: "print the results as JSON",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				opts := &box.SearchOptions{
+					Type:              c.String("type"),
+					FileExtensions:    c.StringSlice("ext"),
+					AncestorFolderIDs: c.StringSlice("ancestor"),
+					ContentTypes:      c.StringSlice("content-type"),
+					MinSize:           c.Int64("min-size"),
+					MaxSize:           c.Int64("max-size"),
+					OwnerUserIDs:      c.StringSlice("owner"),
+					Limit:             c.Int("limit"),
+				}
+				for flag, t := range map[string]*time.Time{
+					"created-after":  &opts.CreatedAfter,
+					"created-before": &opts.CreatedBefore,
+					"updated-after":  &opts.UpdatedAfter,
+					"updated-before": &opts.UpdatedBefore,
+				} {
+					if c.String(flag) == "" {
+						continue
+					}
+					var err error
+					*t, err = parseTime(c.String(flag))
+					if err != nil {
+						log.Fatal(err)
+					}
+				}
+
+				var results struct {
+					Folders  []box.Folder  `json:"folders"`
+					Files    []box.File    `json:"files"`
+					WebLinks []box.WebLink `json:"web_links"`
+				}
+				it := client.Search(strings.Join(c.Args(), " "), opts)
+				for remaining := c.Int("limit"); remaining > 0 && it.Next(); {
+					// Truncate the last page to the limit.
+					folders, files, webLinks := it.Page().Folders, it.Page().Files, it.Page().WebLinks
+					if len(folders) > remaining {
+						folders = folders[:remaining]
+					}
+					remaining -= len(folders)
+					if len(files) > remaining {
+						files = files[:remaining]
+					}
+					remaining -= len(files)
+					if len(webLinks) > remaining {
+						webLinks = webLinks[:remaining]
+					}
+					remaining -= len(webLinks)
+					results.Folders = append(results.Folders, folders...)
+					results.Files = append(results.Files, files...)
+					results.WebLinks = append(results.WebLinks, webLinks...)
+				}
+				if err := it.Err(); err != nil {
+					log.Fatal(err)
+				}
+
+				if c.Bool("json") {
+					out, err := json.MarshalIndent(results, "", "  ")
+					if err != nil {
+						log.Fatal(err)
+					}
+					fmt.Println(string(out))
+					return nil
+				}
+				fmt.Println("Folders:")
+				for _, fd := range results.Folders {
+					fmt.Println(fd.Name + " " + fd.ID)
+				}
+				fmt.Println("---")
+				fmt.Println("Files:")
+				for _, fe := range results.Files {
+					fmt.Println(fe.Name + " " + fe.ID)
+				}
+				if len(results.WebLinks) > 0 {
+					fmt.Println("---")
+					fmt.Println("Web links:")
+					for _, wl := range results.WebLinks {
+						fmt.Println(wl.Name + " " + wl.ID + " " + wl.URL)
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Name:  "collab",
+			Usage: "List, add, change & remove the collaborators of a folder",
+			Subcommands: []cli.Command{
+				{
+					Name:  "ls",
+					Usage: "List the collaborators of a folder",
+					Action: func(c *cli.Context) error {
+						if c.NArg() < 1 {
+							log.Fatal("Specify folder path")
+						}
+						folder, err := sync.ResolveFolderPath(client, c.Args().First())
+						if err != nil {
+							log.Fatal(err)
+						}
+						collaborations, err := client.GetFolderCollaborations(folder.ID)
+						if err != nil {
+							log.Fatal(err)
+						}
+						for _, collab := range collaborations {
+							who := collab.AccessibleBy.Login
+							if who == "" {
+								who = collab.AccessibleBy.Name
+							}
+							if who == "" {
+								who = collab.InviteEmail
+							}
+							fmt.Println(collab.ID + " " + collab.AccessibleBy.Type + " " + who + " " + collab.Role + " " + collab.Status)
+						}
+						return nil
+					},
+				},
+				{
+					Name:  "add",
+					Usage: "Give a user, by login or ID, or a group, by ID, access to one or more folders",
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "role",
+							Value: box.RoleEditor,
+							Usage: "role of the collaborator, e.g. editor, viewer, previewer, uploader or co-owner",
+						},
+						cli.BoolFlag{
+							Name:  "group",
+							Usage: "the collaborator is a group id",
+						},
+					},
+					Action: func(c *cli.Context) error {
+						if c.NArg() < 2 {
+							log.Fatal("Specify login or id & folder paths")
+						}
+						collaborator := box.Collaborator{Type: box.TypeUser, ID: c.Args().First()}
+						switch {
+						case c.Bool("group"):
+							collaborator.Type = box.TypeGroup
+						case strings.Contains(collaborator.ID, "@"):
+							collaborator.ID, collaborator.Login = "", collaborator.ID
+						}
+
+						for _, folderPath := range c.Args().Tail() {
+							folder, err := sync.ResolveFolderPath(client, folderPath)
+							if err != nil {
+								log.Fatal(err)
+							}
+							collab, err := client.AddCollaboration(folder.ID, collaborator, c.String("role"))
+							if err != nil {
+								log.Fatal(err)
+							}
+							fmt.Println("Added " + c.Args().First() + " to " + folderPath + " as " + collab.Role + " " + collab.ID)
+						}
+						return nil
+					},
+				},
+				{
+					Name:  "set-role",
+					Usage: "Change the role of a collaborator",
+					Action: func(c *cli.Context) error {
+						if c.NArg() < 2 {
+							log.Fatal("Specify collaboration id & role")
+						}
+						collab, err := client.SetCollaborationRole(c.Args().First(), c.Args().Get(1))
+						if err != nil {
+							log.Fatal(err)
+						}
+						fmt.Println("Role changed to " + collab.Role)
+						return nil
+					},
+				},
+				{
+					Name:  "rm",
+					Usage: "Remove a collaborator",
+					Action: func(c *cli.Context) error {
+						if c.NArg() < 1 {
+							log.Fatal("Specify collaboration id")
+						}
+						err := client.DeleteCollaboration(c.Args().First())
+						if err != nil {
+							log.Fatal(err)
+						}
+						fmt.Println("Collaborator removed")
+						return nil
+					},
+				},
+			},
+		},
+		{
+			Name:  "admin",
+			Usage: "Manage the users & groups of the enterprise (admins only)",
+			Subcommands: []cli.Command{
+				{
+					Name:  "user",
+					Usage: "List, create, update, deactivate & transfer the content of enterprise users",
+					Subcommands: []cli.Command{
+						{
+							Name:  "ls",
+							Usage: "List the enterprise users, optionally those whose name or login starts with a filter",
+							Action: func(c *cli.Context) error {
+								users, err := client.GetEnterpriseUsers(c.Args().First())
+								if err != nil {
+									log.Fatal(err)
+								}
+								for _, user := range users {
+									fmt.Printf("%s %s %q %s %s %d/%d\n", user.ID, user.Login, user.Name, user.Role, user.Status, user.SpaceUsed, user.SpaceAmount)
+								}
+								return nil
+							},
+						},
+						{
+							Name:  "add",
+							Usage: "Create a managed user",
+							Flags: []cli.Flag{
+								cli.StringFlag{
+									Name:  "role",
+									Usage: "role of the user, user or coadmin",
+								},
+								cli.Int64Flag{
+									Name:  "quota",
+									Usage: "storage quota in GB, or -1 for unlimited",
+								},
+							},
+							Action: func(c *cli.Context) error {
+								if c.NArg() < 2 {
+									log.Fatal("Specify login & name")
+								}
+								user, err := client.CreateUser(box.UserAttributes{
+									Login:       c.Args().First(),
+									Name:        c.Args().Get(1),
+									Role:        c.String("role"),
+									SpaceAmount: quotaBytes(c.Int64("quota")),
+								})
+								if err != nil {
+									log.Fatal(err)
+								}
+								fmt.Println("Created user " + user.Login + " " + user.ID)
+								return nil
+							},
+						},
+						{
+							Name:  "set",
+							Usage: "Change the name, login, role, status or quota of a user",
+							Flags: []cli.Flag{
+								cli.StringFlag{
+									Name:  "name",
+									Usage: "new name of the user",
+								},
+								cli.StringFlag{
+									Name:  "login",
+									Usage: "new login of the user",
+								},
+								cli.StringFlag{
+									Name:  "role",
+									Usage: "new role of the user, user or coadmin",
+								},
+								cli.StringFlag{
+									Name:  "status",
+									Usage: "new status of the user, e.g. active or inactive",
+								},
+								cli.Int64Flag{
+									Name:  "quota",
+									Usage: "new storage quota in GB, or -1 for unlimited",
+								},
+							},
+							Action: func(c *cli.Context) error {
+								if c.NArg() < 1 {
+									log.Fatal("Specify user id")
+								}
+								user, err := client.UpdateUser(c.Args().First(), box.UserAttributes{
+									Name:        c.String("name"),
+									Login:       c.String("login"),
+									Role:        c.String("role"),
+									Status:      c.String("status"),
+									SpaceAmount: quotaBytes(c.Int64("quota")),
+								})
+								if err != nil {
+									log.Fatal(err)
+								}
+								fmt.Println("Updated user " + user.Login)
+								return nil
+							},
+						},
+						{
+							Name:  "deactivate",
+							Usage: "Stop one or more users from logging in, keeping their content",
+							Action: func(c *cli.Context) error {
+								if c.NArg() < 1 {
+									log.Fatal("Specify user ids")
+								}
+								for _, id := range c.Args() {
+									user, err := client.DeactivateUser(id)
+									if err != nil {
+										log.Fatal(err)
+									}
+									fmt.Println("Deactivated " + user.Login)
+								}
+								return nil
+							},
+						},
+						{
+							Name:  "transfer",
+							Usage: "Move all the content owned by a user into a folder of another user",
+							Flags: []cli.Flag{
+								cli.BoolFlag{
+									Name:  "notify",
+									Usage: "email the source user about the transfer",
+								},
+							},
+							Action: func(c *cli.Context) error {
+								if c.NArg() < 2 {
+									log.Fatal("Specify source & destination user ids")
+								}
+								folder, err := client.TransferOwnedContent(c.Args().First(), c.Args().Get(1), c.Bool("notify"))
+								if err != nil {
+									log.Fatal(err)
+								}
+								fmt.Printf("Content moved to %q %s\n", folder.Name, folder.ID)
+								return nil
+							},
+						},
+					},
+				},
+				{
+					Name:  "group",
+					Usage: "List, create & delete groups and manage their members",
+					Subcommands: []cli.Command{
+						{
+							Name:  "ls",
+							Usage: "List the groups of the enterprise",
+							Action: func(c *cli.Context) error {
+								groups, err := client.GetGroups()
+								if err != nil {
+									log.Fatal(err)
+								}
+								for _, group := range groups {
+									fmt.Printf("%s %q %s\n", group.ID, group.Name, group.Description)
+								}
+								return nil
+							},
+						},
+						{
+							Name:  "add",
+							Usage: "Create a group",
+							Flags: []cli.Flag{
+								cli.StringFlag{
+									Name:  "description",
+									Usage: "description of the group",
+								},
+							},
+							Action: func(c *cli.Context) error {
+								if c.NArg() < 1 {
+									log.Fatal("Specify group name")
+								}
+								group, err := client.CreateGroup(c.Args().First(), c.String("description"))
+								if err != nil {
+									log.Fatal(err)
+								}
+								fmt.Println("Created group " + group.Name + " " + group.ID)
+								return nil
+							},
+						},
+						{
+							Name:  "rm",
+							Usage: "Delete a group",
+							Action: func(c *cli.Context) error {
+								if c.NArg() < 1 {
+									log.Fatal("Specify group id")
+								}
+								err := client.DeleteGroup(c.Args().First())
+								if err != nil {
+									log.Fatal(err)
+								}
+								fmt.Println("Group deleted")
+								return nil
+							},
+						},
+						{
+							Name:  "members",
+							Usage: "List the members of a group",
+							Action: func(c *cli.Context) error {
+								if c.NArg() < 1 {
+									log.Fatal("Specify group id")
+								}
+								memberships, err := client.GetGroupMembers(c.Args().First())
+								if err != nil {
+									log.Fatal(err)
+								}
+								for _, membership := range memberships {
+									fmt.Println(membership.ID + " " + membership.User.ID + " " + membership.User.Login + " " + membership.Role)
+								}
+								return nil
+							},
+						},
+						{
+							Name:  "add-member",
+							Usage: "Add one or more users, by ID, to a group",
+							Flags: []cli.Flag{
+								cli.BoolFlag{
+									Name:  "admin",
+									Usage: "make the users admins of the group",
+								},
+							},
+							Action: func(c *cli.Context) error {
+								if c.NArg() < 2 {
+									log.Fatal("Specify group id & user ids")
+								}
+								role := box.GroupRoleMember
+								if c.Bool("admin") {
+									role = box.GroupRoleAdmin
+								}
+								for _, userID := range c.Args().Tail() {
+									membership, err := client.AddGroupMember(c.Args().First(), userID, role)
+									if err != nil {
+										log.Fatal(err)
+									}
+									fmt.Println("Added " + userID + " as " + membership.Role + " " + membership.ID)
+								}
+								return nil
+							},
+						},
+						{
+							Name:  "rm-member",
+							Usage: "Remove a member from a group",
+							Action: func(c *cli.Context) error {
+								if c.NArg() < 1 {
+									log.Fatal("Specify membership id")
+								}
+								err := client.RemoveGroupMember(c.Args().First())
+								if err != nil {
+									log.Fatal(err)
+								}
+								fmt.Println("Member removed")
+								return nil
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:  "comment",
+			Usage: "List, add, edit & delete the comments on a file",
+			Subcommands: []cli.Command{
+				{
+					Name:  "ls",
+					Usage: "List the comments on a file",
+					Action: func(c *cli.Context) error {
+						if c.NArg() < 1 {
+							log.Fatal("Specify file path")
+						}
+						file, err := sync.ResolveFilePath(client, c.Args().First())
+						if err != nil {
+							log.Fatal(err)
+						}
+						comments, err := client.GetFileComments(file.ID)
+						if err != nil {
+							log.Fatal(err)
+						}
+						for _, comment := range comments {
+							indent := ""
+							if comment.IsReplyComment {
+								indent = "  "
+							}
+							fmt.Println(indent + comment.ID + " " + comment.CreatedAt.Format(timeFormat) + " " +
+								comment.CreatedBy.Login + ": " + comment.Message)
+						}
+						return nil
+					},
+				},
+				{
+					Name:  "add",
+					Usage: "Comment on a file",
+					Action: func(c *cli.Context) error {
+						if c.NArg() < 2 {
+							log.Fatal("Specify file path & message")
+						}
+						file, err := sync.ResolveFilePath(client, c.Args().First())
+						if err != nil {
+							log.Fatal(err)
+						}
+						comment, err := client.AddComment(file.ID, strings.Join(c.Args().Tail(), " "))
+						if err != nil {
+							log.Fatal(err)
+						}
+						fmt.Println("Added comment " + comment.ID)
+						return nil
+					},
+				},
+				{
+					Name:  "reply",
+					Usage: "Reply to a comment",
+					Action: func(c *cli.Context) error {
+						if c.NArg() < 2 {
+							log.Fatal("Specify comment id & message")
+						}
+						comment, err := client.ReplyToComment(c.Args().First(), strings.Join(c.Args().Tail(), " "))
+						if err != nil {
+							log.Fatal(err)
+						}
+						fmt.Println("Added reply " + comment.ID)
+						return nil
+					},
+				},
+				{
+					Name:  "edit",
+					Usage: "Replace the text of a comment",
+					Action: func(c *cli.Context) error {
+						if c.NArg() < 2 {
+							log.Fatal("Specify comment id & message")
+						}
+						_, err := client.UpdateComment(c.Args().First(), strings.Join(c.Args().Tail(), " "))
+						if err != nil {
+							log.Fatal(err)
+						}
+						fmt.Println("Comment updated")
+						return nil
+					},
+				},
+				{
+					Name:  "rm",
+					Usage: "Delete a comment",
+					Action: func(c *cli.Context) error {
+						if c.NArg() < 1 {
+							log.Fatal("Specify comment id")
+						}
+						err := client.DeleteComment(c.Args().First())
+						if err != nil {
+							log.Fatal(err)
+						}
+						fmt.Println("Comment deleted")
+						return nil
+					},
+				},
+			},
+		},
+		{
+			Name:  "task",
+			Usage: "List, create, assign & resolve the tasks on a file",
+			Subcommands: []cli.Command{
+				{
+					Name:  "ls",
+					Usage: "List the tasks on a file with their assignees",
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  "overdue",
+							Usage: "only list incomplete assignments of tasks past their due date",
+						},
+					},
+					Action: func(c *cli.Context) error {
+						if c.NArg() < 1 {
+							log.Fatal("Specify file path")
+						}
+						file, err := sync.ResolveFilePath(client, c.Args().First())
+						if err != nil {
+							log.Fatal(err)
+						}
+						tasks, err := client.GetFileTasks(file.ID)
+						if err != nil {
+							log.Fatal(err)
+						}
+						now := time.Now()
+						for _, task := range tasks {
+							if c.Bool("overdue") && (task.DueAt == nil || task.DueAt.After(now)) {
+								continue
+							}
+							fmt.Println(task.ID + " " + task.Action + " " + formatOptionalTime(task.DueAt, "-") + " " + task.Message)
+							for _, assignment := range task.TaskAssignmentCollection.Entries {
+								if c.Bool("overdue") && assignment.ResolutionState != box.ResolutionStateIncomplete {
+									continue
+								}
+								fmt.Println("  " + assignment.ID + " " + assignment.AssignedTo.Login + " " + assignment.ResolutionState)
+							}
+						}
+						return nil
+					},
+				},
+				{
+					Name:  "add",
+					Usage: "Create a task on a file and assign it to users by login or ID",
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "action",
+							Value: box.TaskActionReview,
+							Usage: "what the assignees should do, review or complete",
+						},
+						cli.StringFlag{
+							Name:  "due",
+							Usage: "when the task is due, as a date, a time or a duration from now, e.g. 2026-12-31 or 7d",
+						},
+						cli.StringSliceFlag{
+							Name:  "assign",
+							Usage: "login or id of a user to assign the task to, may be repeated",
+						},
+					},
+					Action: func(c *cli.Context) error {
+						if c.NArg() < 2 {
+							log.Fatal("Specify file path & message")
+						}
+						file, err := sync.ResolveFilePath(client, c.Args().First())
+						if err != nil {
+							log.Fatal(err)
+						}
+						var due *time.Time
+						if c.String("due") != "" {
+							t, err := parseExpiry(c.String("due"), time.Now())
+							if err != nil {
+								log.Fatal(err)
+							}
+							due = &t
+						}
+						task, err := client.CreateTask(file.ID, c.String("action"), strings.Join(c.Args().Tail(), " "), due)
+						if err != nil {
+							log.Fatal(err)
+						}
+						fmt.Println("Created task " + task.ID)
+						for _, user := range c.StringSlice("assign") {
+							assignment, err := client.AssignTask(task.ID, taskAssignee(user))
+							if err != nil {
+								log.Fatal(err)
+							}
+							fmt.Println("Assigned to " + user + " " + assignment.ID)
+						}
+						return nil
+					},
+				},
+				{
+					Name:  "assign",
+					Usage: "Assign a task to one or more users by login or ID",
+					Action: func(c *cli.Context) error {
+						if c.NArg() < 2 {
+							log.Fatal("Specify task id & logins or ids")
+						}
+						for _, user := range c.Args().Tail() {
+							assignment, err := client.AssignTask(c.Args().First(), taskAssignee(user))
+							if err != nil {
+								log.Fatal(err)
+							}
+							fmt.Println("Assigned to " + user + " " + assignment.ID)
+						}
+						return nil
+					},
+				},
+				{
+					Name:  "set-status",
+					Usage: "Resolve a task assignment as completed, incomplete, approved or rejected",
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "message",
+							Usage: "message for the task creator",
+						},
+					},
+					Action: func(c *cli.Context) error {
+						if c.NArg() < 2 {
+							log.Fatal("Specify assignment id & status")
+						}
+						assignment, err := client.UpdateTaskAssignment(c.Args().First(), c.Args().Get(1), c.String("message"))
+						if err != nil {
+							log.Fatal(err)
+						}
+						fmt.Println("Assignment " + assignment.ResolutionState)
+						return nil
+					},
+				},
+				{
+					Name:  "unassign",
+					Usage: "Remove a task assignment",
+					Action: func(c *cli.Context) error {
+						if c.NArg() < 1 {
+							log.Fatal("Specify assignment id")
+						}
+						err := client.DeleteTaskAssignment(c.Args().First())
+						if err != nil {
+							log.Fatal(err)
+						}
+						fmt.Println("Assignment removed")
+						return nil
+					},
+				},
+				{
+					Name:  "rm",
+					Usage: "Delete a task",
+					Action: func(c *cli.Context) error {
+						if c.NArg() < 1 {
+							log.Fatal("Specify task id")
+						}
+						err := client.DeleteTask(c.Args().First())
+						if err != nil {
+							log.Fatal(err)
+						}
+						fmt.Println("Task deleted")
+						return nil
+					},
+				},
+			},
+		},
+		{
+			Name:  "meta",
+			Usage: "Read, set, remove & query the metadata of files & folders",
+			Subcommands: []cli.Command{
+				{
+					Name:  "templates",
+					Usage: "List the metadata templates of the enterprise",
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  "global",
+							Usage: "list the global templates instead",
+						},
+					},
+					Action: func(c *cli.Context) error {
+						scope := box.MetadataScopeEnterprise
+						if c.Bool("global") {
+							scope = box.MetadataScopeGlobal
+						}
+						templates, err := client.GetMetadataTemplates(scope)
+						if err != nil {
+							log.Fatal(err)
+						}
+						for _, template := range templates {
+							var fields []string
+							for _, field := range template.Fields {
+								fields = append(fields, field.Key+":"+field.Type)
+							}
+							fmt.Println(template.Scope + "." + template.TemplateKey + " " + strings.Join(fields, " "))
+						}
+						return nil
+					},
+				},
+				{
+					Name:  "get",
+					Usage: "Print the metadata of a file or folder as JSON, for one template or all of them",
+					Action: func(c *cli.Context) error {
+						if c.NArg() < 1 {
+							log.Fatal("Specify file or folder path")
+						}
+						file, folder, err := sync.ResolvePath(client, c.Args().First())
+						if err != nil {
+							log.Fatal(err)
+						}
+
+						var metadata interface{}
+						if c.NArg() < 2 {
+							if file != nil {
+								metadata, err = client.GetFileMetadataList(file.ID)
+							} else {
+								metadata, err = client.GetFolderMetadataList(folder.ID)
+							}
+						} else {
+							scope, templateKey := metadataTemplate(c.Args().Get(1))
+							if file != nil {
+								metadata, err = client.GetFileMetadata(file.ID, scope, templateKey)
+							} else {
+								metadata, err = client.GetFolderMetadata(folder.ID, scope, templateKey)
+							}
+						}
+						if err != nil {
+							log.Fatal(err)
+						}
+						out, err := json.MarshalIndent(metadata, "", "  ")
+						if err != nil {
+							log.Fatal(err)
+						}
+						fmt.Println(string(out))
+						return nil
+					},
+				},
+				{
+					Name:  "set",
+					Usage: "Set metadata fields of a file or folder, applying the template if needed",
+					Action: func(c *cli.Context) error {
+						if c.NArg() < 3 {
+							log.Fatal("Specify file or folder path, template & key=value pairs")
+						}
+						file, folder, err := sync.ResolvePath(client, c.Args().First())
+						if err != nil {
+							log.Fatal(err)
+						}
+						scope, templateKey := metadataTemplate(c.Args().Get(1))
+						template, err := client.GetMetadataTemplate(scope, templateKey)
+						if err != nil {
+							log.Fatal(err)
+						}
+						values, err := metadataValues(template, c.Args()[2:])
+						if err != nil {
+							log.Fatal(err)
+						}
+
+						if file != nil {
+							_, err = client.CreateFileMetadata(file.ID, scope, templateKey, values)
+						} else {
+							_, err = client.CreateFolderMetadata(folder.ID, scope, templateKey, values)
+						}
+						if box.IsConflict(err) {
+							// The template is already applied, so only the
+							// given fields are changed.
+							var ops []box.MetadataOperation
+							for _, key := range box.Metadata(values).Keys() {
+								ops = append(ops, box.MetadataOperation{Op: box.MetadataOpAdd, Path: "/" + key, Value: values[key]})
+							}
+							if file != nil {
+								_, err = client.UpdateFileMetadata(file.ID, scope, templateKey, ops)
+							} else {
+								_, err = client.UpdateFolderMetadata(folder.ID, scope, templateKey, ops)
+							}
+						}
+						if err != nil {
+							log.Fatal(err)
+						}
+						fmt.Println("Metadata set")
+						return nil
+					},
+				},
+				{
+					Name:  "rm",
+					Usage: "Remove a template, or only some of its fields, from a file or folder",
+					Action: func(c *cli.Context) error {
+						if c.NArg() < 2 {
+							log.Fatal("Specify file or folder path & template")
+						}
+						file, folder, err := sync.ResolvePath(client, c.Args().First())
+						if err != nil {
+							log.Fatal(err)
+						}
+						scope, templateKey := metadataTemplate(c.Args().Get(1))
+
+						if c.NArg() == 2 {
+							if file != nil {
+								err = client.DeleteFileMetadata(file.ID, scope, templateKey)
+							} else {
+								err = client.DeleteFolderMetadata(folder.ID, scope, templateKey)
+							}
+						} else {
+							var ops []box.MetadataOperation
+							for _, key := range c.Args()[2:] {
+								ops = append(ops, box.MetadataOperation{Op: box.MetadataOpRemove, Path: "/" + key})
+							}
+							if file != nil {
+								_, err = client.UpdateFileMetadata(file.ID, scope, templateKey, ops)
+							} else {
+								_, err = client.UpdateFolderMetadata(folder.ID, scope, templateKey, ops)
+							}
+						}
+						if err != nil {
+							log.Fatal(err)
+						}
+						fmt.Println("Metadata removed")
+						return nil
+					},
+				},
+				{
+					Name:  "query",
+					Usage: "List the files & folders whose metadata matches a query",
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "folder",
+							Usage: "path of the folder to search in, all files by default",
+						},
+						cli.StringSliceFlag{
+							Name:  "field",
+							Usage: "template field to print for each result, may be repeated",
+						},
+					},
+					Action: func(c *cli.Context) error {
+						if c.NArg() < 1 {
+							log.Fatal("Specify template, query & key=value parameters")
+						}
+						scope, templateKey := metadataTemplate(c.Args().First())
+						template, err := client.GetMetadataTemplate(scope, templateKey)
+						if err != nil {
+							log.Fatal(err)
+						}
+						// Queries need the scope with the enterprise ID.
+						from := template.Scope + "." + template.TemplateKey
+
+						query := box.MetadataQuery{
+							From:             from,
+							Query:            c.Args().Get(1),
+							AncestorFolderID: "0",
+							Fields:           []string{"name"},
+						}
+						if c.NArg() > 2 {
+							query.QueryParams, err = metadataValues(template, c.Args()[2:])
+							if err != nil {
+								log.Fatal(err)
+							}
+						}
+						if c.String("folder") != "" {
+							folder, err := sync.ResolveFolderPath(client, c.String("folder"))
+							if err != nil {
+								log.Fatal(err)
+							}
+							query.AncestorFolderID = folder.ID
+						}
+						for _, field := range c.StringSlice("field") {
+							query.Fields = append(query.Fields, "metadata."+from+"."+field)
+						}
+
+						results, err := client.QueryMetadata(query)
+						if err != nil {
+							log.Fatal(err)
+						}
+						for _, fd := range results.Folders {
+							fmt.Println(fd.Name + " " + fd.ID + metadataFields(fd.Metadata, template, c.StringSlice("field")))
+						}
+						for _, fe := range results.Files {
+							fmt.Println(fe.Name + " " + fe.ID + metadataFields(fe.Metadata, template, c.StringSlice("field")))
+						}
+						return nil
+					},
+				},
+			},
+		},
+		{
+			Name:  "trash",
+			Usage: "List, restore & permanently delete trashed items",
+			Subcommands: []cli.Command{
+				{
+					Name:  "ls",
+					Usage: "List all files & folders in the trash",
+					Action: func(c *cli.Context) error {
+						var folders []box.Folder
+						var files []box.File
+						it := client.GetTrashItems(nil)
+						for it.Next() {
+							folders = append(folders, it.Page().Folders...)
+							files = append(files, it.Page().Files...)
+						}
+						if err := it.Err(); err != nil {
+							log.Fatal(err)
+						}
+
+						fmt.Println("Folders:")
+						for _, fd := range folders {
+							fmt.Println(fd.Name + " " + fd.ID + " " + formatOptionalTime(fd.TrashedAt, ""))
+						}
+						fmt.Println("---")
+						fmt.Println("Files:")
+						for _, fe := range files {
+							fmt.Println(fe.Name + " " + fe.ID + " " + formatOptionalTime(fe.TrashedAt, ""))
+						}
+						return nil
+					},
+				},
+				{
+					Name:  "restore",
+					Usage: "Restore file or folder from the trash",
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  "folder",
+							Usage: "the id is a folder id",
+						},
+						cli.StringFlag{
+							Name:  "name",
+							Usage: "new name of the restored item",
+						},
+					},
+					Action: func(c *cli.Context) error {
+						if c.NArg() < 1 {
+							log.Fatal("Specify file or folder id")
+						}
+						parentId := c.Args().Get(1)
+
+						var name, id string
+						var err error
+						if c.Bool("folder") {
+							var folder *box.Folder
+							folder, err = client.RestoreFolder(c.Args().First(), parentId, c.String("name"))
+							if folder != nil {
+								name, id = folder.Name, folder.ID
+							}
+						} else {
+							var file *box.File
+							file, err = client.RestoreFile(c.Args().First(), parentId, c.String("name"))
+							if file != nil {
+								name, id = file.Name, file.ID
+							}
+						}
+
+						if err != nil {
+							log.Fatal(err)
+						}
+						fmt.Println("Restore successful")
+						fmt.Println("Name & ID: " + name + " " + id)
+						return nil
+					},
+				},
+				{
+					Name:  "purge",
+					Usage: "Permanently delete file or folder from the trash",
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  "folder",
+							Usage: "the id is a folder id",
+						},
+					},
+					Action: func(c *cli.Context) error {
+						if c.NArg() < 1 {
+							log.Fatal("Specify file or folder id")
+						}
+
+						var err error
+						if c.Bool("folder") {
+							err = client.PurgeFolder(c.Args().First())
+						} else {
+							err = client.PurgeFile(c.Args().First())
+						}
+
+						if err != nil {
+							log.Fatal(err)
+						}
+						fmt.Println("Permanently deleted")
+						return nil
+					},
+				},
+			},
+		},
 		{
 			Name:    "ls",
 			Aliases: []string{"ls"},
@@ -213,6 +1870,13 @@ func main() {
 				for _, fe := range felist {
 					fmt.Println(fe.Name + " " + fe.ID)
 				}
+				if len(fcontent.WebLinks) > 0 {
+					fmt.Println("---")
+					fmt.Println("Web links:")
+					for _, wl := range fcontent.WebLinks {
+						fmt.Println(wl.Name + " " + wl.ID + " " + wl.URL)
+					}
+				}
 				//fmt.Println(fcontent.Folders)
 				return nil
 			},
@@ -221,3 +1885,194 @@ func main() {
 
 	app.Run(os.Args)
 }
+
+// quotaBytes converts a storage quota in GB to bytes, keeping -1 for
+// unlimited and 0 for no change.
+func quotaBytes(gb int64) int64 {
+	if gb <= 0 {
+		return gb
+	}
+	return gb << 30
+}
+
+// parseTime parses s as a time in RFC 3339 format or as a local date.
+func parseTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, errors.New("Invalid date or time " + s)
+}
+
+// parseExpiry parses s as a date, a time or a duration from now, which may
+// be given in days, e.g. "7d".
+func parseExpiry(s string, now time.Time) (time.Time, error) {
+	if t, err := parseTime(s); err == nil {
+		return t, nil
+	}
+	if strings.HasSuffix(s, "d") {
+		if days, err := strconv.Atoi(strings.TrimSuffix(s, "d")); err == nil {
+			return now.AddDate(0, 0, days), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(d), nil
+	}
+	return time.Time{}, errors.New("Invalid expiry " + s)
+}
+
+// downloadPermission returns the shared link permissions that allow or
+// prevent downloads, or nil to keep the current ones.
+func downloadPermission(allow, prevent bool) (*box.SharedLinkPermissionUpdate, error) {
+	switch {
+	case allow && prevent:
+		return nil, errors.New("Specify either --download or --no-download")
+	case allow || prevent:
+		return &box.SharedLinkPermissionUpdate{CanDownload: allow}, nil
+	}
+	return nil, nil
+}
+
+// formatOptionalTime formats t, or returns none if t is nil.
+func formatOptionalTime(t *time.Time, none string) string {
+	if t == nil {
+		return none
+	}
+	return t.Format(timeFormat)
+}
+
+// taskAssignee identifies user by login if it looks like an email address
+// and by ID otherwise.
+func taskAssignee(user string) box.TaskAssignee {
+	if strings.Contains(user, "@") {
+		return box.TaskAssignee{Login: user}
+	}
+	return box.TaskAssignee{ID: user}
+}
+
+// metadataTemplate splits name, given as [scope.]templateKey, into its scope
+// and key. The scope defaults to the enterprise.
+func metadataTemplate(name string) (scope, templateKey string) {
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		return name[:i], name[i+1:]
+	}
+	return box.MetadataScopeEnterprise, name
+}
+
+// metadataValues parses args given as key=value into values for the fields of
+// template, converting them to the type of each field. Values of multiSelect
+// fields are separated by commas.
+func metadataValues(template *box.MetadataTemplate, args []string) (map[string]interface{}, error) {
+	fieldTypes := map[string]string{}
+	for _, field := range template.Fields {
+		fieldTypes[field.Key] = field.Type
+	}
+
+	values := map[string]interface{}{}
+	for _, arg := range args {
+		i := strings.Index(arg, "=")
+		if i < 0 {
+			return nil, errors.New("Invalid value " + arg + ", expected key=value")
+		}
+		key, value := arg[:i], arg[i+1:]
+		switch fieldTypes[key] {
+		case box.MetadataFieldFloat:
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, errors.New("Invalid number " + value + " for " + key)
+			}
+			values[key] = f
+		case box.MetadataFieldDate:
+			t, err := parseTime(value)
+			if err != nil {
+				return nil, err
+			}
+			values[key] = t.Format(time.RFC3339)
+		case box.MetadataFieldMultiSelect:
+			values[key] = strings.Split(value, ",")
+		default:
+			values[key] = value
+		}
+	}
+	return values, nil
+}
+
+// metadataFields formats the values of fields of the template instance in
+// metadata for printing after an item.
+func metadataFields(metadata box.MetadataByScope, template *box.MetadataTemplate, fields []string) string {
+	instance := metadata[template.Scope][template.TemplateKey]
+	s := ""
+	for _, field := range fields {
+		s += " " + field + "=" + fmt.Sprint(instance[field])
+	}
+	return s
+}
+
+// updateFavorites adds the files or folders at the paths given to c to the
+// favorites, or removes them, with update.
+func updateFavorites(client box.Client, c *cli.Context, update func(collectionID, itemType, itemID string) error, done string) error {
+	if c.NArg() < 1 {
+		log.Fatal("Specify file or folder paths")
+	}
+	favorites, err := client.GetFavorites()
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, itemPath := range c.Args() {
+		file, folder, err := sync.ResolvePath(client, itemPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if file != nil {
+			err = update(favorites.ID, box.TypeFile, file.ID)
+		} else {
+			err = update(favorites.ID, box.TypeFolder, folder.ID)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(done + itemPath)
+	}
+	return nil
+}
+
+// extractedText writes the text extracted from the file fileID to w, waiting
+// for Box to extract it if needed.
+func extractedText(client box.Client, fileID string, w io.Writer) error {
+	reps, err := client.GetRepresentations(fileID, box.RepresentationExtractedText)
+	if err != nil {
+		return err
+	}
+	for _, rep := range reps {
+		if rep.Representation != box.RepresentationExtractedText {
+			continue
+		}
+		ready, err := client.WaitForRepresentation(&rep)
+		if err != nil {
+			return err
+		}
+		return client.DownloadRepresentation(ready, "", w)
+	}
+	return errors.New("no text can be extracted from this file")
+}
+
+// fileVersions returns the file at filePath and its previous versions, newest
+// first.
+func fileVersions(client box.Client, filePath string) (*box.File, []box.FileVersion) {
+	file, err := sync.ResolveFilePath(client, filePath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// The listing used to find the file does not include who modified it.
+	file, err = client.GetFile(file.ID)
+	if err != nil {
+		log.Fatal(err)
+	}
+	versions, err := client.GetFileVersions(file.ID)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return file, versions
+}
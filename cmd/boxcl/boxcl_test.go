@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseExpiry(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	expiry, err := parseExpiry("2026-12-31", now)
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, time.Date(2026, 12, 31, 0, 0, 0, 0, time.Local), expiry, "Date should be parsed in local time")
+
+	expiry, err = parseExpiry("2026-12-31T08:30:00Z", now)
+	assert.NoError(t, err, "Function should not return error")
+	assert.True(t, expiry.Equal(time.Date(2026, 12, 31, 8, 30, 0, 0, time.UTC)), "Time should be parsed")
+
+	expiry, err = parseExpiry("7d", now)
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, now.AddDate(0, 0, 7), expiry, "Days should be added to now")
+
+	expiry, err = parseExpiry("72h", now)
+	assert.NoError(t, err, "Function should not return error")
+	assert.Equal(t, now.Add(72*time.Hour), expiry, "Duration should be added to now")
+
+	_, err = parseExpiry("soon", now)
+	assert.Error(t, err, "Invalid expiry should return error")
+	_, err = parseExpiry("xd", now)
+	assert.Error(t, err, "Invalid number of days should return error")
+}
+
+func TestDownloadPermission(t *testing.T) {
+	permissions, err := downloadPermission(false, false)
+	assert.NoError(t, err, "Function should not return error")
+	assert.Nil(t, permissions, "Permissions should be kept by default")
+
+	permissions, err = downloadPermission(false, true)
+	assert.NoError(t, err, "Function should not return error")
+	assert.False(t, permissions.CanDownload, "Downloads should be prevented")
+
+	permissions, err = downloadPermission(true, false)
+	assert.NoError(t, err, "Function should not return error")
+	assert.True(t, permissions.CanDownload, "Downloads should be allowed again")
+
+	_, err = downloadPermission(true, true)
+	assert.Error(t, err, "Conflicting flags should return error")
+}
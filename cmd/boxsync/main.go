@@ -7,6 +7,8 @@ import (
 	"os/signal"
 	"path"
 
+	"golang.org/x/net/context"
+
 	"gitlab.engr.illinois.edu/sp-box/boxsync/auth"
 	"gitlab.engr.illinois.edu/sp-box/boxsync/box"
 	"gitlab.engr.illinois.edu/sp-box/boxsync/cache"
@@ -67,26 +69,57 @@ func main() {
 		}
 	}
 
-	cache, err := cache.NewCache(client)
+	// Cancelling ctx on a kill signal stops any transfer in progress,
+	// including the initial refresh.
+	ctx, cancel := context.WithCancel(context.Background())
+	killSignalC := make(chan os.Signal, 1)
+	signal.Notify(killSignalC, os.Interrupt, os.Kill)
+	go func() {
+		<-killSignalC
+		log.Print("Kill signal triggered, quit...")
+		cancel()
+	}()
+
+	cache, err := cache.NewCache(ctx, client)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	killSignalC := make(chan os.Signal, 1)
-	signal.Notify(killSignalC, os.Interrupt, os.Kill)
-
 	watcher := filemonitor.NewWatcher(func(*filemonitor.FileWatchEvent) {
-		_ = cache.RescanLocalTree()
+		_ = cache.RescanLocalTree(ctx)
 	})
+	watcher.AddExcludePatterns(".*" + box.PartialDownloadSuffix)
 	watcher.AddAll(boxRoot)
 
+	// Remote moves and renames are applied to the local tree as they
+	// happen.
+	var events <-chan box.Event
+	var eventErrs <-chan error
+	longPollURL, err := client.GetLongPollURL()
+	if err == nil {
+		events, eventErrs, err = client.WithContext(ctx).GetEventStream(longPollURL, box.StreamPositionNow, ctx.Done())
+	}
+	if err != nil {
+		log.Printf("Failed to watch remote events: %v", err)
+	}
+
 	for {
 		select {
 		case <-watcher.FileEventC:
-		case <-killSignalC:
+		case event := <-events:
+			err := cache.HandleEvent(ctx, event)
+			if err != nil {
+				log.Printf("Failed to apply %s event: %v", event.EventType, err)
+			}
+		case err := <-eventErrs:
+			log.Printf("Stopped watching remote events: %v", err)
+			events, eventErrs = nil, nil
+		case <-ctx.Done():
 			//for now just handle kill signals
-			log.Print("Kill signal triggered, quit...")
 			watcher.Close()
+			api, upload := client.RequestStats()
+			log.Printf("API requests: %d (%d delayed, %v waiting)", api.Requests, api.Delayed, api.TotalWait)
+			log.Printf("Upload requests: %d (%d delayed, %v waiting)", upload.Requests, upload.Delayed, upload.TotalWait)
 			return
 		}
 	}
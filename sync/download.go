@@ -42,27 +42,32 @@ func DownloadAll(client box.Client, folderID, destPath string) error {
 	}
 
 	fmt.Printf("Downloading folder %s to %s\n", folderID, destPath)
-	contents, err := client.GetFolderContents(folderID)
-	if err != nil {
-		return err
-	}
-
-	for _, file := range contents.Files {
-		filePath := path.Join(destPath, file.Name)
-
-		if _, err := os.Stat(filePath); err == nil && SHA1(filePath) == file.SHA1 {
-			fmt.Printf("Checksums match, skipping: %s\n", filePath)
-			continue
-		}
+	var folders []box.Folder
+	it := client.GetFolderItems(folderID, nil)
+	for it.Next() {
+		contents := it.Page()
+		folders = append(folders, contents.Folders...)
+
+		for _, file := range contents.Files {
+			filePath := path.Join(destPath, file.Name)
+
+			if _, err := os.Stat(filePath); err == nil && SHA1(filePath) == file.SHA1 {
+				fmt.Printf("Checksums match, skipping: %s\n", filePath)
+				continue
+			}
 
-		fmt.Printf("Downloading file %s to %s\n", file.ID, filePath)
-		err = client.DownloadFile(file.ID, filePath)
-		if err != nil {
-			return err
+			fmt.Printf("Downloading file %s to %s\n", file.ID, filePath)
+			err = client.DownloadFile(file.ID, filePath)
+			if err != nil {
+				return err
+			}
 		}
 	}
+	if err := it.Err(); err != nil {
+		return err
+	}
 
-	for _, folder := range contents.Folders {
+	for _, folder := range folders {
 		folderPath := path.Join(destPath, folder.Name)
 
 		if _, err := os.Stat(folderPath); os.IsNotExist(err) {
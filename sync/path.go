@@ -0,0 +1,89 @@
+package sync
+
+import (
+	"errors"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gitlab.engr.illinois.edu/sp-box/boxsync/box"
+)
+
+// pathItemFields are the fields requested while walking a path, enough to
+// match names and tell files from folders.
+var pathItemFields = []string{"name", "sequence_id", "etag", "sha1", "size", "parent"}
+
+// ResolvePath returns the file or folder at p, exactly one of which is
+// non-nil. p is either a path inside LocalSyncRoot, or a path in Box starting
+// from All Files, e.g. "Box Sync/report.xlsx".
+func ResolvePath(client box.Client, p string) (*box.File, *box.Folder, error) {
+	if rel, err := filepath.Rel(LocalSyncRoot, p); err == nil && filepath.IsAbs(p) && !strings.HasPrefix(rel, "..") {
+		p = path.Join(syncRootName, filepath.ToSlash(rel))
+	}
+
+	p = strings.Trim(path.Clean("/"+filepath.ToSlash(p)), "/")
+	if p == "" {
+		folder, err := client.GetFolder("0")
+		return nil, folder, err
+	}
+
+	names := strings.Split(p, "/")
+	folderID := "0"
+	for i, name := range names[:len(names)-1] {
+		_, folder, err := findItem(client, folderID, name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if folder == nil {
+			return nil, nil, errors.New(path.Join(names[:i+1]...) + " is not a folder")
+		}
+		folderID = folder.ID
+	}
+	return findItem(client, folderID, names[len(names)-1])
+}
+
+// ResolveFilePath returns the file at p, as for ResolvePath.
+func ResolveFilePath(client box.Client, p string) (*box.File, error) {
+	file, _, err := ResolvePath(client, p)
+	if err != nil {
+		return nil, err
+	}
+	if file == nil {
+		return nil, errors.New(p + " is not a file")
+	}
+	return file, nil
+}
+
+// ResolveFolderPath returns the folder at p, as for ResolvePath.
+func ResolveFolderPath(client box.Client, p string) (*box.Folder, error) {
+	_, folder, err := ResolvePath(client, p)
+	if err != nil {
+		return nil, err
+	}
+	if folder == nil {
+		return nil, errors.New(p + " is not a folder")
+	}
+	return folder, nil
+}
+
+// findItem returns the file or folder named name in the folder folderID.
+func findItem(client box.Client, folderID, name string) (*box.File, *box.Folder, error) {
+	it := client.GetFolderItems(folderID, &box.ItemsOptions{Fields: pathItemFields})
+	for it.Next() {
+		page := it.Page()
+		for _, folder := range page.Folders {
+			if folder.Name == name {
+				return nil, &folder, nil
+			}
+		}
+		for _, file := range page.Files {
+			if file.Name == name {
+				return &file, nil, nil
+			}
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, nil, err
+	}
+	return nil, nil, errors.New(name + " does not exist in folder " + folderID)
+}